@@ -0,0 +1,148 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"kontest-api/config"
+	"kontest-api/controllers"
+	"kontest-api/database"
+	"kontest-api/middleware"
+	"kontest-api/repository"
+	"kontest-api/repository/impl"
+	"kontest-api/routes"
+	"kontest-api/service"
+	"kontest-api/sources"
+	"net/http"
+)
+
+// Application holds the wired repositories, services and handlers of one configuration.
+// Several applications can live in the same process, e.g. in tests.
+type Application struct {
+	Config             *config.Config
+	DB                 *gorm.DB
+	KontestRepository  repository.KontestRepository
+	MetadataRepository repository.MetadataRepository
+	SourceRegistry     *sources.Registry
+	KontestService     *service.KontestService
+	RefreshScheduler   *service.RefreshScheduler
+	KontestHandler     *controllers.KontestHandler
+}
+
+// Builder wires an Application from its configuration. Dependencies that are not given
+// explicitly are created from the configuration when Build is called.
+type Builder struct {
+	cfg                *config.Config
+	db                 *gorm.DB
+	kontestRepository  repository.KontestRepository
+	metadataRepository repository.MetadataRepository
+	sourceRegistry     *sources.Registry
+}
+
+// NewBuilder creates a new Builder for the given configuration
+func NewBuilder(cfg *config.Config) *Builder {
+	return &Builder{cfg: cfg}
+}
+
+// WithDatabase sets the database used by the GORM repositories and the health checks
+func (b *Builder) WithDatabase(db *gorm.DB) *Builder {
+	b.db = db
+	return b
+}
+
+// WithRepositories sets the repositories instead of creating them for the configured driver
+func (b *Builder) WithRepositories(kontestRepository repository.KontestRepository, metadataRepository repository.MetadataRepository) *Builder {
+	b.kontestRepository = kontestRepository
+	b.metadataRepository = metadataRepository
+	return b
+}
+
+// WithSourceRegistry sets the contest sources instead of creating them from the configuration
+func (b *Builder) WithSourceRegistry(registry *sources.Registry) *Builder {
+	b.sourceRegistry = registry
+	return b
+}
+
+// Build creates the Application, loading the stored contests within the context.
+// The refresh scheduler is not started.
+func (b *Builder) Build(ctx context.Context) (*Application, error) {
+	kontestRepository, metadataRepository := b.kontestRepository, b.metadataRepository
+	if kontestRepository == nil || metadataRepository == nil {
+		switch {
+		case b.cfg.Database.Driver == "memory":
+			kontestRepository = impl.NewInMemoryKontestRepository()
+			metadataRepository = impl.NewInMemoryMetadataRepository()
+		case b.db != nil:
+			// Postgres and SQLite are both accessed through GORM
+			kontestRepository = impl.NewKontestRepository(b.db, b.cfg.Database.QueryTimeout.Std())
+			metadataRepository = impl.NewMetadataRepository(b.db, b.cfg.Database.QueryTimeout.Std())
+		default:
+			return nil, errors.New("a database is required for the " + b.cfg.Database.Driver + " driver")
+		}
+	}
+
+	sourceRegistry := b.sourceRegistry
+	if sourceRegistry == nil {
+		sourceRegistry = newSourceRegistry(b.cfg)
+	}
+
+	merger := service.NewKontestMerger(b.cfg.Refresh.SourcePriority, b.cfg.Refresh.MergeStartTolerance.Std())
+	kontestService := service.NewKontestService(ctx, kontestRepository, metadataRepository, sourceRegistry, merger, b.cfg.Refresh.SnapshotRetention.Std())
+	refreshScheduler := newRefreshScheduler(b.cfg, kontestService, sourceRegistry)
+
+	var pingDatabase func(ctx context.Context) error
+	if b.db != nil {
+		db, timeout := b.db, b.cfg.Database.QueryTimeout.Std()
+		pingDatabase = func(ctx context.Context) error {
+			ctx, cancel := context.WithTimeout(ctx, timeout)
+			defer cancel()
+			return database.Ping(ctx, db)
+		}
+	}
+
+	return &Application{
+		Config:             b.cfg,
+		DB:                 b.db,
+		KontestRepository:  kontestRepository,
+		MetadataRepository: metadataRepository,
+		SourceRegistry:     sourceRegistry,
+		KontestService:     kontestService,
+		RefreshScheduler:   refreshScheduler,
+		KontestHandler:     controllers.NewKontestHandler(kontestService, refreshScheduler, pingDatabase, b.cfg.Server.MaxPerPage),
+	}, nil
+}
+
+// Close stops the application once the context passed to RefreshScheduler.Start is cancelled: it
+// waits for the scheduler to abandon a running refresh and then closes the database pool.
+// It gives up waiting when ctx is done.
+func (a *Application) Close(ctx context.Context) error {
+	var errs []error
+	if err := a.RefreshScheduler.Wait(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("refresh scheduler did not stop: %w", err))
+	}
+
+	if a.DB != nil {
+		if err := database.Close(a.DB); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close database: %w", err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// Handler returns the HTTP handler serving every route, wrapped in the configured middleware
+func (a *Application) Handler() http.Handler {
+	router := http.NewServeMux()
+
+	routes.RegisterRoutes(router, a.KontestHandler)
+
+	// Every request gets an ID first, so that it is available to the other middleware
+	middlewares := []middleware.Middleware{middleware.RequestID}
+	if a.Config.Logging.Requests {
+		middlewares = append(middlewares, middleware.Logging)
+	}
+	stack := middleware.CreateStack(middlewares...)
+
+	// Requests matching no route get JSON errors like every other endpoint
+	return stack(controllers.FallbackHandler(router))
+}
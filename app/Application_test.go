@@ -0,0 +1,269 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"kontest-api/config"
+	"kontest-api/model"
+	"kontest-api/repository/impl"
+	"kontest-api/sources"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// fakeSource is a contest source returning fixed contests
+type fakeSource struct {
+	name     string
+	kontests []model.KontestModel
+}
+
+func (f *fakeSource) Name() string {
+	return f.name
+}
+
+func (f *fakeSource) Fetch(ctx context.Context) ([]model.KontestModel, error) {
+	return slices.Clone(f.kontests), nil
+}
+
+// newTestServer builds an application with in-memory repositories, refreshes it from a fake source
+// with the given number of upcoming contests and serves its handler
+func newTestServer(t *testing.T, count int) (*httptest.Server, *Application) {
+	t.Helper()
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	codeforces := &fakeSource{name: "codeforces"}
+	for i := range count {
+		codeforces.kontests = append(codeforces.kontests, *model.NewKontestModel(
+			fmt.Sprintf("Codeforces Round %d", i+1),
+			fmt.Sprintf("https://codeforces.com/contest/%d", i+1),
+			start.Add(time.Duration(i)*time.Hour),
+			start.Add(time.Duration(i)*time.Hour+2*time.Hour),
+			"codeforces.com",
+		))
+	}
+	registry := sources.NewRegistry()
+	registry.Register(codeforces)
+
+	cfg := config.Default()
+	cfg.Database.Driver = "memory"
+	cfg.Logging.Requests = false
+
+	app, err := NewBuilder(cfg).
+		WithRepositories(impl.NewInMemoryKontestRepository(), impl.NewInMemoryMetadataRepository()).
+		WithSourceRegistry(registry).
+		Build(context.Background())
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if err := app.KontestService.RefreshSources(context.Background(), []string{"codeforces"}); err != nil {
+		t.Fatalf("RefreshSources failed: %v", err)
+	}
+
+	server := httptest.NewServer(app.Handler())
+	t.Cleanup(server.Close)
+	return server, app
+}
+
+// getJSON requests the path and decodes the JSON body into v, returning the response
+func getJSON(t *testing.T, server *httptest.Server, path string, v any) *http.Response {
+	t.Helper()
+	resp, err := http.Get(server.URL + path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if got := resp.Header.Get("Content-Type"); got != "application/json" {
+		t.Errorf("GET %s: Content-Type = %q, want application/json", path, got)
+	}
+	if err := json.NewDecoder(resp.Body).Decode(v); err != nil {
+		t.Fatalf("GET %s: failed to decode body: %v", path, err)
+	}
+	return resp
+}
+
+type listResponse struct {
+	Contests []struct {
+		ID         string            `json:"id"`
+		Name       string            `json:"name"`
+		StartTime  string            `json:"start_time"`
+		Status     string            `json:"status"`
+		Source     string            `json:"source"`
+		Provenance map[string]string `json:"provenance"`
+	} `json:"contests"`
+	Total      int     `json:"total"`
+	PerPage    int     `json:"per_page"`
+	NextCursor *string `json:"next_cursor"`
+	PrevCursor *string `json:"prev_cursor"`
+}
+
+type errorBody struct {
+	Code      string         `json:"code"`
+	Message   string         `json:"message"`
+	Details   map[string]any `json:"details"`
+	RequestID string         `json:"request_id"`
+}
+
+func TestGetAllKontests(t *testing.T) {
+	server, _ := newTestServer(t, 5)
+
+	var page listResponse
+	resp := getJSON(t, server, "/kontests?per_page=2", &page)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if page.Total != 5 || page.PerPage != 2 || len(page.Contests) != 2 {
+		t.Fatalf("page = %d contests of %d with per_page %d, want 2 of 5 with per_page 2", len(page.Contests), page.Total, page.PerPage)
+	}
+	if resp.Header.Get("X-Total-Count") != "5" {
+		t.Errorf("X-Total-Count = %q, want 5", resp.Header.Get("X-Total-Count"))
+	}
+	first := page.Contests[0]
+	if first.Name != "Codeforces Round 1" || first.Status != "upcoming" || first.Source != "codeforces" || first.Provenance["name"] != "codeforces" {
+		t.Errorf("first contest = %+v, want an upcoming Codeforces Round 1 from codeforces", first)
+	}
+	if page.PrevCursor != nil || page.NextCursor == nil {
+		t.Fatalf("cursors = %v %v, want only a next cursor on the first page", page.PrevCursor, page.NextCursor)
+	}
+
+	// Following the next cursors visits every contest once
+	names := []string{page.Contests[0].Name, page.Contests[1].Name}
+	for page.NextCursor != nil {
+		path := "/kontests?per_page=2&cursor=" + url.QueryEscape(*page.NextCursor)
+		page = listResponse{}
+		if resp := getJSON(t, server, path, &page); resp.StatusCode != http.StatusOK {
+			t.Fatalf("GET %s: status = %d, want 200", path, resp.StatusCode)
+		}
+		if page.PrevCursor == nil {
+			t.Errorf("GET %s: prev_cursor is null after the first page", path)
+		}
+		for _, contest := range page.Contests {
+			names = append(names, contest.Name)
+		}
+	}
+	want := []string{"Codeforces Round 1", "Codeforces Round 2", "Codeforces Round 3", "Codeforces Round 4", "Codeforces Round 5"}
+	if !slices.Equal(names, want) {
+		t.Errorf("paged contests = %v, want %v", names, want)
+	}
+
+	var sorted listResponse
+	getJSON(t, server, "/kontests?sort=-start_time&status=upcoming&per_page=1", &sorted)
+	if len(sorted.Contests) != 1 || sorted.Contests[0].Name != "Codeforces Round 5" {
+		t.Errorf("sorted by -start_time = %+v, want Codeforces Round 5 first", sorted.Contests)
+	}
+}
+
+func TestGetKontest(t *testing.T) {
+	server, _ := newTestServer(t, 1)
+
+	var page listResponse
+	getJSON(t, server, "/kontests", &page)
+	if len(page.Contests) != 1 {
+		t.Fatalf("GET /kontests returned %d contests, want 1", len(page.Contests))
+	}
+
+	var contest struct {
+		ID              string            `json:"id"`
+		Name            string            `json:"name"`
+		DurationSeconds int64             `json:"duration_seconds"`
+		Source          string            `json:"source"`
+		Provenance      map[string]string `json:"provenance"`
+	}
+	resp := getJSON(t, server, "/kontests/"+page.Contests[0].ID, &contest)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status = %d, want 200", resp.StatusCode)
+	}
+	if contest.ID != page.Contests[0].ID || contest.Name != "Codeforces Round 1" || contest.Source != "codeforces" || contest.Provenance["url"] != "codeforces" {
+		t.Errorf("contest = %+v, want Codeforces Round 1 with its provenance", contest)
+	}
+	if contest.DurationSeconds != 7200 {
+		t.Errorf("duration_seconds = %d, want 7200", contest.DurationSeconds)
+	}
+
+	for _, id := range []string{"0190a5a4-7c1e-7000-8000-000000000000", "not-a-uuid"} {
+		var body errorBody
+		resp := getJSON(t, server, "/kontests/"+id, &body)
+		if resp.StatusCode != http.StatusNotFound || body.Code != "not_found" {
+			t.Errorf("GET /kontests/%s = %d %q, want 404 not_found", id, resp.StatusCode, body.Code)
+		}
+		if body.RequestID == "" || body.RequestID != resp.Header.Get("X-Request-ID") {
+			t.Errorf("GET /kontests/%s: request_id = %q, want the X-Request-ID header %q", id, body.RequestID, resp.Header.Get("X-Request-ID"))
+		}
+	}
+}
+
+func TestGetAllKontestsInvalidParameters(t *testing.T) {
+	server, _ := newTestServer(t, 3)
+
+	var page listResponse
+	getJSON(t, server, "/kontests?per_page=1", &page)
+	if page.NextCursor == nil {
+		t.Fatal("next_cursor is null, want a cursor")
+	}
+	cursor := url.QueryEscape(*page.NextCursor)
+
+	tests := []struct {
+		query string
+		param string
+	}{
+		{query: "status=planned", param: "status"},
+		{query: "sort=rating", param: "sort"},
+		{query: "sort=start_time,-start_time", param: "sort"},
+		{query: "starts_after=yesterday", param: "starts_after"},
+		{query: "starts_after=2024-07-02T00:00:00Z&starts_before=2024-07-01T00:00:00Z", param: "starts_after"},
+		{query: "min_duration=-1h", param: "min_duration"},
+		{query: "per_page=0", param: "per_page"},
+		{query: "per_page=many", param: "per_page"},
+		{query: "page=0", param: "page"},
+		{query: "cursor=not-a-cursor", param: "cursor"},
+		{query: "per_page=1&page=2&cursor=" + cursor, param: "cursor"},
+		{query: "per_page=2&cursor=" + cursor, param: "cursor"},
+		{query: "per_page=1&status=upcoming&cursor=" + cursor, param: "cursor"},
+	}
+	for _, tt := range tests {
+		var body errorBody
+		resp := getJSON(t, server, "/kontests?"+tt.query, &body)
+		if resp.StatusCode != http.StatusBadRequest || body.Code != "invalid_parameter" {
+			t.Errorf("GET /kontests?%s = %d %q, want 400 invalid_parameter", tt.query, resp.StatusCode, body.Code)
+			continue
+		}
+		if body.Details["parameter"] != tt.param {
+			t.Errorf("GET /kontests?%s: parameter = %v, want %s", tt.query, body.Details["parameter"], tt.param)
+		}
+		if body.Message == "" {
+			t.Errorf("GET /kontests?%s: message is empty", tt.query)
+		}
+	}
+}
+
+func TestUnmatchedRoutes(t *testing.T) {
+	server, _ := newTestServer(t, 1)
+
+	var body errorBody
+	resp := getJSON(t, server, "/contests", &body)
+	if resp.StatusCode != http.StatusNotFound || body.Code != "not_found" || body.RequestID == "" {
+		t.Errorf("GET /contests = %d %+v, want 404 not_found with a request ID", resp.StatusCode, body)
+	}
+
+	resp, err := http.Post(server.URL+"/kontests", "application/json", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	body = errorBody{}
+	if err := json.NewDecoder(resp.Body).Decode(&body); err != nil {
+		t.Fatalf("POST /kontests: failed to decode body: %v", err)
+	}
+	if resp.StatusCode != http.StatusMethodNotAllowed || body.Code != "method_not_allowed" {
+		t.Errorf("POST /kontests = %d %q, want 405 method_not_allowed", resp.StatusCode, body.Code)
+	}
+	if allow := resp.Header.Get("Allow"); !strings.Contains(allow, "GET") {
+		t.Errorf("POST /kontests: Allow = %q, want it to list GET", allow)
+	}
+}
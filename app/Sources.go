@@ -0,0 +1,55 @@
+package app
+
+import (
+	"kontest-api/config"
+	"kontest-api/service"
+	"kontest-api/sources"
+	"maps"
+	"net/http"
+	"slices"
+	"time"
+)
+
+// newSourceRegistry creates the registry of contest sources used by the application.
+// Every known source is registered, and the ones not enabled in the configuration are disabled.
+func newSourceRegistry(cfg *config.Config) *sources.Registry {
+	registry := sources.NewRegistry()
+
+	for _, name := range slices.Sorted(maps.Keys(cfg.Sources)) {
+		sourceConfig := cfg.Sources[name]
+		client := &http.Client{Timeout: sourceConfig.Timeout.Std()}
+
+		var source sources.ContestSource
+		switch name {
+		case "clist":
+			source = sources.NewClistSource(client, sourceConfig.BaseURL)
+		case "clist-api":
+			source = sources.NewClistAPISource(client, sourceConfig.BaseURL, sourceConfig.Username, sourceConfig.APIKey, sourceConfig.Window.Std())
+		case "codeforces":
+			source = sources.NewCodeforcesSource(client, sourceConfig.BaseURL, sourceConfig.IncludeGym)
+		case "leetcode":
+			source = sources.NewLeetCodeSource(client, sourceConfig.BaseURL)
+		case "atcoder":
+			source = sources.NewAtCoderSource(client, sourceConfig.BaseURL)
+		case "codechef":
+			source = sources.NewCodeChefSource(client, sourceConfig.BaseURL)
+		default:
+			continue
+		}
+
+		registry.Register(source)
+		if !sourceConfig.Enabled {
+			registry.Disable(name)
+		}
+	}
+	return registry
+}
+
+// newRefreshScheduler creates the scheduler that refreshes the sources in the background
+func newRefreshScheduler(cfg *config.Config, kontestService *service.KontestService, sourceRegistry *sources.Registry) *service.RefreshScheduler {
+	intervals := make(map[string]time.Duration, len(cfg.Sources))
+	for name := range cfg.Sources {
+		intervals[name] = cfg.IntervalFor(name)
+	}
+	return service.NewRefreshScheduler(kontestService, sourceRegistry, cfg.Refresh.Interval.Std(), intervals, cfg.Refresh.Jitter.Std(), cfg.Refresh.Timeout.Std())
+}
@@ -0,0 +1,262 @@
+package config
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"io"
+	"os"
+	"time"
+)
+
+// Config is the complete application configuration.
+type Config struct {
+	Server   ServerConfig            `yaml:"server"`
+	Database DatabaseConfig          `yaml:"database"`
+	Refresh  RefreshConfig           `yaml:"refresh"`
+	Sources  map[string]SourceConfig `yaml:"sources"`
+	Logging  LoggingConfig           `yaml:"logging"`
+}
+
+// ServerConfig configures the HTTP server.
+type ServerConfig struct {
+	Port         string   `yaml:"port"`
+	ReadTimeout  Duration `yaml:"read_timeout"`
+	WriteTimeout Duration `yaml:"write_timeout"`
+	IdleTimeout  Duration `yaml:"idle_timeout"`
+
+	// ShutdownTimeout bounds how long in-flight requests and a running refresh are waited
+	// for when the process is asked to stop
+	ShutdownTimeout Duration `yaml:"shutdown_timeout"`
+
+	// MaxPerPage is the largest page size a client may request
+	MaxPerPage int `yaml:"max_per_page"`
+}
+
+// DatabaseConfig configures the storage backend, the database connection and its pool.
+// If DSN is set it is used as is and the individual connection fields are ignored.
+type DatabaseConfig struct {
+	// Driver selects the storage backend: "postgres", "sqlite" (a single file, no server needed)
+	// or "memory" (nothing is persisted across restarts)
+	Driver string `yaml:"driver"`
+
+	SQLitePath      string   `yaml:"sqlite_path"`
+	DSN             string   `yaml:"dsn"`
+	Host            string   `yaml:"host"`
+	Port            string   `yaml:"port"`
+	Name            string   `yaml:"name"`
+	User            string   `yaml:"user"`
+	Password        string   `yaml:"password"`
+	SSLMode         string   `yaml:"sslmode"`
+	MaxOpenConns    int      `yaml:"max_open_conns"`
+	MaxIdleConns    int      `yaml:"max_idle_conns"`
+	ConnMaxLifetime Duration `yaml:"conn_max_lifetime"`
+	QueryTimeout    Duration `yaml:"query_timeout"` // Deadline of every database operation
+	AutoMigrate     bool     `yaml:"auto_migrate"`
+
+	// ConnectRetries is the number of times connecting is retried at startup, with the delay
+	// starting at ConnectRetryDelay and doubling after every attempt
+	ConnectRetries    int      `yaml:"connect_retries"`
+	ConnectRetryDelay Duration `yaml:"connect_retry_delay"`
+
+	// OnUnavailable decides what happens if the database cannot be reached at startup:
+	// "exit" stops the process, "degraded" serves contests from memory until the database is back
+	OnUnavailable string `yaml:"on_unavailable"`
+}
+
+// RefreshConfig configures how and how often the contest sources are refreshed.
+type RefreshConfig struct {
+	Interval            Duration `yaml:"interval"`
+	Jitter              Duration `yaml:"jitter"`
+	Timeout             Duration `yaml:"timeout"` // Deadline of a refresh, from fetching to persisting
+	MergeStartTolerance Duration `yaml:"merge_start_tolerance"`
+	SourcePriority      []string `yaml:"source_priority"`
+
+	// SnapshotRetention is how long the contests replaced by a refresh are kept, so that
+	// pagination cursors handed out before the refresh keep returning consistent pages
+	SnapshotRetention Duration `yaml:"snapshot_retention"`
+}
+
+// SourceConfig configures a single contest source. Fields that do not apply to a source are ignored.
+type SourceConfig struct {
+	Enabled    bool     `yaml:"enabled"`
+	BaseURL    string   `yaml:"base_url"`
+	Interval   Duration `yaml:"interval,omitempty"` // Falls back to refresh.interval when unset
+	Timeout    Duration `yaml:"timeout"`
+	IncludeGym bool     `yaml:"include_gym,omitempty"` // codeforces only
+	Username   string   `yaml:"username,omitempty"`    // clist-api only
+	APIKey     string   `yaml:"api_key,omitempty"`     // clist-api only
+	Window     Duration `yaml:"window,omitempty"`      // clist-api only
+}
+
+// LoggingConfig configures logging.
+type LoggingConfig struct {
+	Requests bool   `yaml:"requests"`  // Log every HTTP request
+	SQLLevel string `yaml:"sql_level"` // One of silent, error, warn or info
+}
+
+// Default returns the configuration used for any setting that is not configured explicitly.
+func Default() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Port:            "5151",
+			ReadTimeout:     Duration(10 * time.Second),
+			WriteTimeout:    Duration(30 * time.Second),
+			IdleTimeout:     Duration(2 * time.Minute),
+			ShutdownTimeout: Duration(15 * time.Second),
+			MaxPerPage:      100,
+		},
+		Database: DatabaseConfig{
+			Driver:            "postgres",
+			SQLitePath:        "kontest.db",
+			Host:              "localhost",
+			Port:              "5432",
+			Name:              "kontest",
+			User:              "postgres",
+			Password:          "postgres",
+			SSLMode:           "disable",
+			MaxOpenConns:      10,
+			MaxIdleConns:      5,
+			ConnMaxLifetime:   Duration(30 * time.Minute),
+			QueryTimeout:      Duration(10 * time.Second),
+			ConnectRetries:    5,
+			ConnectRetryDelay: Duration(2 * time.Second),
+			OnUnavailable:     "exit",
+		},
+		Refresh: RefreshConfig{
+			Interval:            Duration(time.Hour),
+			Jitter:              Duration(5 * time.Minute),
+			Timeout:             Duration(5 * time.Minute),
+			SnapshotRetention:   Duration(15 * time.Minute),
+			MergeStartTolerance: Duration(15 * time.Minute),
+			// First-party sources take priority over the clist aggregator
+			SourcePriority: []string{"codeforces", "leetcode", "atcoder", "codechef", "clist-api", "clist"},
+		},
+		Sources: map[string]SourceConfig{
+			"clist": {
+				Enabled: true,
+				BaseURL: "https://clist.by",
+				Timeout: Duration(30 * time.Second),
+			},
+			"clist-api": {
+				Enabled: false,
+				BaseURL: "https://clist.by",
+				Timeout: Duration(30 * time.Second),
+				Window:  Duration(30 * 24 * time.Hour),
+			},
+			"codeforces": {
+				Enabled: true,
+				BaseURL: "https://codeforces.com",
+				Timeout: Duration(30 * time.Second),
+			},
+			"leetcode": {
+				Enabled: true,
+				BaseURL: "https://leetcode.com",
+				// LeetCode only publishes weekly and biweekly contests, so it is polled less often
+				Interval: Duration(6 * time.Hour),
+				Timeout:  Duration(30 * time.Second),
+			},
+			"atcoder": {
+				Enabled: true,
+				BaseURL: "https://atcoder.jp",
+				Timeout: Duration(30 * time.Second),
+			},
+			"codechef": {
+				Enabled: true,
+				BaseURL: "https://www.codechef.com",
+				Timeout: Duration(30 * time.Second),
+			},
+		},
+		Logging: LoggingConfig{
+			Requests: true,
+			SQLLevel: "info",
+		},
+	}
+}
+
+// Load builds the configuration from the defaults, the YAML file at path (if path is not empty)
+// and environment variable overrides, in that order, and validates the result.
+func Load(path string) (*Config, error) {
+	cfg := Default()
+
+	if path != "" {
+		content, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read config file: %w", err)
+		}
+
+		if err := decodeFile(cfg, content); err != nil {
+			return nil, fmt.Errorf("failed to parse config file %s: %w", path, err)
+		}
+	}
+
+	if err := applyEnv(cfg, os.LookupEnv); err != nil {
+		return nil, err
+	}
+
+	if err := cfg.Validate(); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// decodeFile decodes the YAML content over cfg. Unknown keys are rejected.
+func decodeFile(cfg *Config, content []byte) error {
+	defaults := make(map[string]SourceConfig, len(cfg.Sources))
+	for name, source := range cfg.Sources {
+		defaults[name] = source
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(content))
+	decoder.KnownFields(true)
+	if err := decoder.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return err
+	}
+
+	// Decoding replaces whole map entries, so decode each configured source again on top of
+	// its defaults to keep the settings the file does not mention
+	var file struct {
+		Sources map[string]yaml.Node `yaml:"sources"`
+	}
+	if err := yaml.Unmarshal(content, &file); err != nil {
+		return err
+	}
+
+	for name, node := range file.Sources {
+		source := defaults[name]
+		if err := node.Decode(&source); err != nil {
+			return err
+		}
+		defaults[name] = source
+	}
+	cfg.Sources = defaults
+	return nil
+}
+
+// ConnectionString returns the DSN used to connect to the database
+func (d DatabaseConfig) ConnectionString() string {
+	if d.DSN != "" {
+		return d.DSN
+	}
+
+	if d.Driver == "sqlite" {
+		// Wait for locks instead of failing immediately when the refresh and a reader overlap
+		return d.SQLitePath + "?_pragma=busy_timeout(5000)&_pragma=journal_mode(WAL)"
+	}
+
+	if d.Password == "" {
+		return fmt.Sprintf("host=%s user=%s dbname=%s port=%s sslmode=%s",
+			d.Host, d.User, d.Name, d.Port, d.SSLMode)
+	}
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
+		d.Host, d.User, d.Password, d.Name, d.Port, d.SSLMode)
+}
+
+// IntervalFor returns the refresh interval of the named source
+func (c *Config) IntervalFor(name string) time.Duration {
+	if source, ok := c.Sources[name]; ok && source.Interval > 0 {
+		return source.Interval.Std()
+	}
+	return c.Refresh.Interval.Std()
+}
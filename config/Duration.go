@@ -0,0 +1,30 @@
+package config
+
+import (
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"time"
+)
+
+// Duration is a time.Duration that is written to and read from YAML as a string such as "1h30m".
+type Duration time.Duration
+
+// UnmarshalYAML parses a duration string such as "90s" or "1h".
+func (d *Duration) UnmarshalYAML(value *yaml.Node) error {
+	parsed, err := time.ParseDuration(value.Value)
+	if err != nil {
+		return fmt.Errorf("line %d: invalid duration %q", value.Line, value.Value)
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+// MarshalYAML writes the duration in its string form.
+func (d Duration) MarshalYAML() (interface{}, error) {
+	return time.Duration(d).String(), nil
+}
+
+// Std returns the duration as a time.Duration.
+func (d Duration) Std() time.Duration {
+	return time.Duration(d)
+}
@@ -0,0 +1,118 @@
+package config
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// envPrefix is the prefix shared by every environment variable that overrides the configuration
+const envPrefix = "KONTEST_API_"
+
+// applyEnv overrides the configuration with the KONTEST_API_* environment variables.
+func applyEnv(cfg *Config, lookup func(string) (string, bool)) error {
+	env := envReader{lookup: lookup}
+
+	env.string("SERVER_PORT", &cfg.Server.Port)
+	env.duration("SERVER_READ_TIMEOUT", &cfg.Server.ReadTimeout)
+	env.duration("SERVER_WRITE_TIMEOUT", &cfg.Server.WriteTimeout)
+	env.duration("SERVER_IDLE_TIMEOUT", &cfg.Server.IdleTimeout)
+	env.duration("SERVER_SHUTDOWN_TIMEOUT", &cfg.Server.ShutdownTimeout)
+	env.int("SERVER_MAX_PER_PAGE", &cfg.Server.MaxPerPage)
+
+	env.string("DATABASE_DRIVER", &cfg.Database.Driver)
+	env.string("DATABASE_SQLITE_PATH", &cfg.Database.SQLitePath)
+	env.string("DATABASE_DSN", &cfg.Database.DSN)
+	env.string("DATABASE_HOST", &cfg.Database.Host)
+	env.string("DATABASE_PORT", &cfg.Database.Port)
+	env.string("DATABASE_NAME", &cfg.Database.Name)
+	env.string("DATABASE_USER", &cfg.Database.User)
+	env.string("DATABASE_PASSWORD", &cfg.Database.Password)
+	env.string("DATABASE_SSLMODE", &cfg.Database.SSLMode)
+	env.int("DATABASE_MAX_OPEN_CONNS", &cfg.Database.MaxOpenConns)
+	env.int("DATABASE_MAX_IDLE_CONNS", &cfg.Database.MaxIdleConns)
+	env.duration("DATABASE_CONN_MAX_LIFETIME", &cfg.Database.ConnMaxLifetime)
+	env.duration("DATABASE_QUERY_TIMEOUT", &cfg.Database.QueryTimeout)
+	env.bool("DATABASE_AUTO_MIGRATE", &cfg.Database.AutoMigrate)
+	env.int("DATABASE_CONNECT_RETRIES", &cfg.Database.ConnectRetries)
+	env.duration("DATABASE_CONNECT_RETRY_DELAY", &cfg.Database.ConnectRetryDelay)
+	env.string("DATABASE_ON_UNAVAILABLE", &cfg.Database.OnUnavailable)
+
+	env.duration("REFRESH_INTERVAL", &cfg.Refresh.Interval)
+	env.duration("REFRESH_JITTER", &cfg.Refresh.Jitter)
+	env.duration("REFRESH_TIMEOUT", &cfg.Refresh.Timeout)
+	env.duration("REFRESH_SNAPSHOT_RETENTION", &cfg.Refresh.SnapshotRetention)
+
+	env.bool("LOG_REQUESTS", &cfg.Logging.Requests)
+	env.string("LOG_SQL_LEVEL", &cfg.Logging.SQLLevel)
+
+	for name, source := range cfg.Sources {
+		key := "SOURCE_" + strings.ToUpper(strings.ReplaceAll(name, "-", "_")) + "_"
+		env.bool(key+"ENABLED", &source.Enabled)
+		env.string(key+"BASE_URL", &source.BaseURL)
+		env.duration(key+"INTERVAL", &source.Interval)
+		env.duration(key+"TIMEOUT", &source.Timeout)
+		env.string(key+"USERNAME", &source.Username)
+		env.string(key+"API_KEY", &source.APIKey)
+		cfg.Sources[name] = source
+	}
+
+	return env.err
+}
+
+// envReader reads typed values from environment variables, remembering the first error.
+type envReader struct {
+	lookup func(string) (string, bool)
+	err    error
+}
+
+func (e *envReader) get(key string) (string, bool) {
+	if e.err != nil {
+		return "", false
+	}
+	return e.lookup(envPrefix + key)
+}
+
+func (e *envReader) fail(key, value string, err error) {
+	e.err = fmt.Errorf("invalid value %q for %s%s: %w", value, envPrefix, key, err)
+}
+
+func (e *envReader) string(key string, target *string) {
+	if value, ok := e.get(key); ok {
+		*target = value
+	}
+}
+
+func (e *envReader) int(key string, target *int) {
+	if value, ok := e.get(key); ok {
+		parsed, err := strconv.Atoi(value)
+		if err != nil {
+			e.fail(key, value, err)
+			return
+		}
+		*target = parsed
+	}
+}
+
+func (e *envReader) bool(key string, target *bool) {
+	if value, ok := e.get(key); ok {
+		parsed, err := strconv.ParseBool(value)
+		if err != nil {
+			e.fail(key, value, err)
+			return
+		}
+		*target = parsed
+	}
+}
+
+func (e *envReader) duration(key string, target *Duration) {
+	if value, ok := e.get(key); ok {
+		parsed, err := time.ParseDuration(value)
+		if err != nil {
+			e.fail(key, value, err)
+			return
+		}
+		*target = Duration(parsed)
+	}
+}
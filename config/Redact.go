@@ -0,0 +1,53 @@
+package config
+
+import (
+	"gopkg.in/yaml.v3"
+	"net/url"
+	"regexp"
+)
+
+// redacted replaces secrets in the printed configuration
+const redacted = "REDACTED"
+
+// dsnPasswordPattern matches the password of a key=value DSN
+var dsnPasswordPattern = regexp.MustCompile(`(password=)('(?:[^'\\]|\\.)*'|\S+)`)
+
+// Redacted returns a copy of the configuration with every secret replaced.
+func (c *Config) Redacted() *Config {
+	copied := *c
+
+	if copied.Database.Password != "" {
+		copied.Database.Password = redacted
+	}
+	copied.Database.DSN = redactDSN(copied.Database.DSN)
+
+	copied.Sources = make(map[string]SourceConfig, len(c.Sources))
+	for name, source := range c.Sources {
+		if source.APIKey != "" {
+			source.APIKey = redacted
+		}
+		copied.Sources[name] = source
+	}
+	return &copied
+}
+
+// redactDSN hides the password of a URL or key=value DSN
+func redactDSN(dsn string) string {
+	if parsed, err := url.Parse(dsn); err == nil && parsed.User != nil {
+		if _, ok := parsed.User.Password(); ok {
+			parsed.User = url.UserPassword(parsed.User.Username(), redacted)
+			return parsed.String()
+		}
+		return dsn
+	}
+	return dsnPasswordPattern.ReplaceAllString(dsn, "${1}"+redacted)
+}
+
+// YAML returns the configuration with its secrets redacted, formatted as YAML.
+func (c *Config) YAML() (string, error) {
+	out, err := yaml.Marshal(c.Redacted())
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
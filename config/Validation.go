@@ -0,0 +1,140 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"slices"
+	"strconv"
+)
+
+// databaseDrivers are the accepted values of database.driver
+var databaseDrivers = []string{"postgres", "sqlite", "memory"}
+
+// unavailablePolicies are the accepted values of database.on_unavailable
+var unavailablePolicies = []string{"exit", "degraded"}
+
+// sqlLevels are the accepted values of logging.sql_level
+var sqlLevels = []string{"silent", "error", "warn", "info"}
+
+// Validate checks the configuration and returns every problem found, joined into one error.
+func (c *Config) Validate() error {
+	var errs []error
+	invalid := func(format string, args ...any) {
+		errs = append(errs, fmt.Errorf(format, args...))
+	}
+
+	if port, err := strconv.Atoi(c.Server.Port); err != nil || port < 1 || port > 65535 {
+		invalid("server.port must be a port number between 1 and 65535, got %q", c.Server.Port)
+	}
+	if c.Server.ReadTimeout < 0 || c.Server.WriteTimeout < 0 || c.Server.IdleTimeout < 0 {
+		invalid("server timeouts must not be negative")
+	}
+	if c.Server.ShutdownTimeout <= 0 {
+		invalid("server.shutdown_timeout must be positive")
+	}
+	if c.Server.MaxPerPage <= 0 {
+		invalid("server.max_per_page must be positive")
+	}
+
+	switch c.Database.Driver {
+	case "postgres":
+		if c.Database.DSN == "" && (c.Database.Host == "" || c.Database.Name == "" || c.Database.User == "") {
+			invalid("database.dsn or database.host, database.name and database.user must be set")
+		}
+	case "sqlite":
+		if c.Database.DSN == "" && c.Database.SQLitePath == "" {
+			invalid("database.dsn or database.sqlite_path must be set for the sqlite driver")
+		}
+	case "memory":
+	default:
+		invalid("database.driver must be one of %v, got %q", databaseDrivers, c.Database.Driver)
+	}
+	if c.Database.MaxOpenConns < 0 || c.Database.MaxIdleConns < 0 {
+		invalid("database pool sizes must not be negative")
+	}
+	if c.Database.MaxOpenConns > 0 && c.Database.MaxIdleConns > c.Database.MaxOpenConns {
+		invalid("database.max_idle_conns (%d) must not exceed database.max_open_conns (%d)",
+			c.Database.MaxIdleConns, c.Database.MaxOpenConns)
+	}
+	if c.Database.ConnMaxLifetime < 0 {
+		invalid("database.conn_max_lifetime must not be negative")
+	}
+	if c.Database.QueryTimeout <= 0 {
+		invalid("database.query_timeout must be positive")
+	}
+	if c.Database.ConnectRetries < 0 {
+		invalid("database.connect_retries must not be negative")
+	}
+	if c.Database.ConnectRetryDelay <= 0 {
+		invalid("database.connect_retry_delay must be positive")
+	}
+	if !slices.Contains(unavailablePolicies, c.Database.OnUnavailable) {
+		invalid("database.on_unavailable must be one of %v, got %q", unavailablePolicies, c.Database.OnUnavailable)
+	}
+
+	if c.Refresh.Interval <= 0 {
+		invalid("refresh.interval must be positive")
+	}
+	if c.Refresh.Jitter < 0 {
+		invalid("refresh.jitter must not be negative")
+	}
+	if c.Refresh.Timeout <= 0 {
+		invalid("refresh.timeout must be positive")
+	}
+	if c.Refresh.SnapshotRetention < 0 {
+		invalid("refresh.snapshot_retention must not be negative")
+	}
+	if c.Refresh.MergeStartTolerance < 0 {
+		invalid("refresh.merge_start_tolerance must not be negative")
+	}
+
+	known := Default().Sources
+	for _, name := range c.Refresh.SourcePriority {
+		if _, ok := known[name]; !ok {
+			invalid("refresh.source_priority contains unknown source %q", name)
+		}
+	}
+
+	enabled := 0
+	for name, source := range c.Sources {
+		if _, ok := known[name]; !ok {
+			invalid("sources.%s is not a known source", name)
+			continue
+		}
+		if !source.Enabled {
+			continue
+		}
+		enabled++
+
+		if parsed, err := url.Parse(source.BaseURL); err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") || parsed.Host == "" {
+			invalid("sources.%s.base_url must be an http or https URL, got %q", name, source.BaseURL)
+		}
+		if source.Interval < 0 {
+			invalid("sources.%s.interval must not be negative", name)
+		}
+		if source.Timeout <= 0 {
+			invalid("sources.%s.timeout must be positive", name)
+		}
+		if name == "clist-api" {
+			if source.Username == "" || source.APIKey == "" {
+				invalid("sources.clist-api requires username and api_key")
+			}
+			if source.Window <= 0 {
+				invalid("sources.clist-api.window must be positive")
+			}
+		}
+	}
+	if enabled == 0 {
+		invalid("at least one source must be enabled")
+	}
+
+	if !slices.Contains(sqlLevels, c.Logging.SQLLevel) {
+		invalid("logging.sql_level must be one of %v, got %q", sqlLevels, c.Logging.SQLLevel)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %w", errors.Join(errs...))
+	}
+	return nil
+}
@@ -0,0 +1,28 @@
+package main
+
+import (
+	"fmt"
+	"kontest-api/config"
+	"os"
+)
+
+const configUsage = `Usage: kontest-api config <command>
+
+Commands:
+  print       Print the effective configuration with secrets redacted`
+
+// runConfig runs the config subcommand with the given arguments and returns the exit code
+func runConfig(cfg *config.Config, args []string) int {
+	if len(args) != 1 || args[0] != "print" {
+		fmt.Fprintln(os.Stderr, configUsage)
+		return 2
+	}
+
+	out, err := cfg.YAML()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to print config: %v\n", err)
+		return 1
+	}
+	fmt.Print(out)
+	return 0
+}
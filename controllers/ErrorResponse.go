@@ -0,0 +1,156 @@
+package controllers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"kontest-api/middleware"
+	"kontest-api/repository"
+	"kontest-api/service"
+	"log"
+	"net/http"
+)
+
+// Error codes identify the kind of an error response, they are stable across releases
+const (
+	codeInvalidParameter   = "invalid_parameter"
+	codeNotFound           = "not_found"
+	codeCursorExpired      = "cursor_expired"
+	codeRefreshUnavailable = "refresh_unavailable"
+	codeStorageUnavailable = "storage_unavailable"
+	codeTimeout            = "timeout"
+	codeClientClosed       = "client_closed_request"
+	codeMethodNotAllowed   = "method_not_allowed"
+	codeInternalError      = "internal_error"
+)
+
+// statusClientClosedRequest is the non-standard status of requests abandoned by the client.
+// The client never receives it, but it tells these requests apart in the request logs.
+const statusClientClosedRequest = 499
+
+var (
+	// errRouteNotFound is reported for paths that match no route
+	errRouteNotFound = errors.New("route not found")
+
+	// errMethodNotAllowed is reported for paths whose routes do not accept the request method
+	errMethodNotAllowed = errors.New("method not allowed")
+)
+
+// internalErrorMessage is the message of errors whose details are only logged
+const internalErrorMessage = "An internal error occurred, quote the request_id when reporting it"
+
+// errorResponse is the body of every error response
+type errorResponse struct {
+	Code      string         `json:"code"`       // One of the error codes above
+	Message   string         `json:"message"`    // Human-readable description of the error
+	Details   map[string]any `json:"details"`    // Additional context, such as the invalid parameter
+	RequestID string         `json:"request_id"` // ID of the request, also sent in the X-Request-ID header
+}
+
+// paramError reports an invalid request parameter
+type paramError struct {
+	Param   string // Name of the parameter
+	Message string
+}
+
+func (e *paramError) Error() string {
+	return e.Message
+}
+
+// invalidParam returns a paramError for the named parameter with a formatted message
+func invalidParam(param string, format string, args ...any) error {
+	return &paramError{Param: param, Message: fmt.Sprintf(format, args...)}
+}
+
+// writeError responds with the error response matching the type of err. Errors that clients
+// cannot act on are logged with the request ID and reported without their internals.
+func writeError(w http.ResponseWriter, r *http.Request, err error) {
+	response := errorResponse{
+		Code:      codeInternalError,
+		Message:   internalErrorMessage,
+		Details:   map[string]any{},
+		RequestID: middleware.GetRequestID(r.Context()),
+	}
+	status := http.StatusInternalServerError
+
+	var paramErr *paramError
+	switch {
+	case errors.As(err, &paramErr):
+		status, response.Code, response.Message = http.StatusBadRequest, codeInvalidParameter, paramErr.Message
+		response.Details["parameter"] = paramErr.Param
+	case errors.Is(err, service.ErrKontestNotFound):
+		status, response.Code, response.Message = http.StatusNotFound, codeNotFound, "Contest not found"
+	case errors.Is(err, errRouteNotFound):
+		status, response.Code, response.Message = http.StatusNotFound, codeNotFound, "No route matches "+r.URL.Path
+	case errors.Is(err, errMethodNotAllowed):
+		status, response.Code, response.Message = http.StatusMethodNotAllowed, codeMethodNotAllowed, r.Method+" is not allowed on "+r.URL.Path
+	case errors.Is(err, service.ErrSnapshotExpired):
+		status, response.Code, response.Message = http.StatusGone, codeCursorExpired, "The cursor has expired, request the first page again"
+	case errors.Is(err, service.ErrSchedulerStopped):
+		status, response.Code, response.Message = http.StatusServiceUnavailable, codeRefreshUnavailable, "Refreshes are not running, try again later"
+	case errors.Is(err, repository.ErrUnavailable):
+		status, response.Code, response.Message = http.StatusServiceUnavailable, codeStorageUnavailable, "Storage is unavailable, try again later"
+	case errors.Is(err, context.DeadlineExceeded):
+		status, response.Code, response.Message = http.StatusGatewayTimeout, codeTimeout, "The request timed out"
+	case errors.Is(err, context.Canceled):
+		// The client went away, nothing failed on our side
+		status, response.Code, response.Message = statusClientClosedRequest, codeClientClosed, "The client closed the request"
+	}
+
+	if status >= http.StatusInternalServerError {
+		log.Printf("Request %s %s %s failed: %v", response.RequestID, r.Method, r.URL.Path, err)
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(response)
+}
+
+// FallbackHandler serves the requests of the router, answering requests that match no route with
+// a JSON error response instead of the router's plain text one
+func FallbackHandler(router *http.ServeMux) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if _, pattern := router.Handler(r); pattern != "" {
+			router.ServeHTTP(w, r)
+			return
+		}
+
+		// Let the router tell a missing route from a wrong method, keeping only its Allow header
+		recorder := &statusRecorder{header: http.Header{}}
+		router.ServeHTTP(recorder, r)
+		if allow := recorder.header.Get("Allow"); allow != "" {
+			w.Header().Set("Allow", allow)
+		}
+
+		if recorder.status == http.StatusMethodNotAllowed {
+			writeError(w, r, errMethodNotAllowed)
+			return
+		}
+		writeError(w, r, errRouteNotFound)
+	})
+}
+
+// statusRecorder is a response writer that records the status and headers and discards the body
+type statusRecorder struct {
+	header http.Header
+	status int
+}
+
+func (s *statusRecorder) Header() http.Header {
+	return s.header
+}
+
+func (s *statusRecorder) Write(data []byte) (int, error) {
+	if s.status == 0 {
+		s.status = http.StatusOK
+	}
+	return len(data), nil
+}
+
+func (s *statusRecorder) WriteHeader(status int) {
+	if s.status == 0 {
+		s.status = status
+	}
+}
@@ -0,0 +1,34 @@
+package controllers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+func TestWriteErrorClientClosedRequest(t *testing.T) {
+	var logs bytes.Buffer
+	output := log.Writer()
+	log.SetOutput(&logs)
+	t.Cleanup(func() { log.SetOutput(output) })
+
+	recorder := httptest.NewRecorder()
+	request := httptest.NewRequest(http.MethodGet, "/kontests", nil)
+	writeError(recorder, request, fmt.Errorf("failed to query contests: %w", context.Canceled))
+
+	var body errorResponse
+	if err := json.NewDecoder(recorder.Body).Decode(&body); err != nil {
+		t.Fatal(err)
+	}
+	if recorder.Code != statusClientClosedRequest || body.Code != codeClientClosed {
+		t.Errorf("response = %d %q, want %d %q", recorder.Code, body.Code, statusClientClosedRequest, codeClientClosed)
+	}
+	if logs.Len() != 0 {
+		t.Errorf("cancelled request was logged as a failure: %s", logs.String())
+	}
+}
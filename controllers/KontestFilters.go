@@ -0,0 +1,121 @@
+package controllers
+
+import (
+	"kontest-api/service"
+	"kontest-api/utils/enums"
+	"net/url"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// parseKontestFilters reads the contest filters of GET /kontests from the query parameters:
+//
+//	starts_after, starts_before  RFC 3339 times, e.g. 2024-05-01T00:00:00Z
+//	status                       comma-separated list of upcoming, running and finished
+//	min_duration, max_duration   durations such as 90m or 3h, or a number of seconds
+//	q                            case-insensitive substring of the contest name
+//	sort                         comma-separated fields, each prefixed with - to sort descending,
+//	                             e.g. -start_time,site
+//
+// The returned error describes the first invalid parameter.
+func parseKontestFilters(values url.Values, query *service.KontestQuery) error {
+	var err error
+	if query.StartsAfter, err = parseTimeParam(values, "starts_after"); err != nil {
+		return err
+	}
+	if query.StartsBefore, err = parseTimeParam(values, "starts_before"); err != nil {
+		return err
+	}
+	if !query.StartsAfter.IsZero() && !query.StartsBefore.IsZero() && !query.StartsAfter.Before(query.StartsBefore) {
+		return invalidParam("starts_after", "starts_after (%s) must be before starts_before (%s)",
+			values.Get("starts_after"), values.Get("starts_before"))
+	}
+
+	if rawStatuses := values.Get("status"); rawStatuses != "" {
+		for _, rawStatus := range strings.Split(rawStatuses, ",") {
+			status := enums.ContestStatus(strings.ToLower(strings.TrimSpace(rawStatus)))
+			if !slices.Contains(enums.GetAllStatuses(), status) {
+				return invalidParam("status", "invalid status %q: must be one of %v", rawStatus, enums.GetAllStatuses())
+			}
+			query.Statuses = append(query.Statuses, status)
+		}
+	}
+
+	if query.MinDuration, err = parseDurationParam(values, "min_duration"); err != nil {
+		return err
+	}
+	if query.MaxDuration, err = parseDurationParam(values, "max_duration"); err != nil {
+		return err
+	}
+	if query.MinDuration > 0 && query.MaxDuration > 0 && query.MinDuration > query.MaxDuration {
+		return invalidParam("min_duration", "min_duration (%s) must not exceed max_duration (%s)",
+			values.Get("min_duration"), values.Get("max_duration"))
+	}
+
+	query.Text = strings.TrimSpace(values.Get("q"))
+
+	query.Sort, err = parseSortParam(values.Get("sort"))
+	return err
+}
+
+// parseSortParam parses the sort parameter into sort keys, rejecting unknown and repeated fields
+func parseSortParam(raw string) ([]service.SortKey, error) {
+	if raw == "" {
+		return nil, nil
+	}
+
+	var keys []service.SortKey
+	for _, rawKey := range strings.Split(raw, ",") {
+		rawKey = strings.TrimSpace(rawKey)
+
+		key := service.SortKey{Field: service.SortField(strings.TrimPrefix(rawKey, "-"))}
+		key.Descending = strings.HasPrefix(rawKey, "-")
+
+		if !slices.Contains(service.GetAllSortFields(), key.Field) {
+			return nil, invalidParam("sort", "invalid sort field %q: must be one of %v, optionally prefixed with - for descending order",
+				rawKey, service.GetAllSortFields())
+		}
+		if slices.ContainsFunc(keys, func(k service.SortKey) bool { return k.Field == key.Field }) {
+			return nil, invalidParam("sort", "invalid sort %q: %s is sorted by more than once", raw, key.Field)
+		}
+		keys = append(keys, key)
+	}
+	return keys, nil
+}
+
+// parseTimeParam parses the named parameter as an RFC 3339 time, returning the zero time if it is not set
+func parseTimeParam(values url.Values, name string) (time.Time, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return time.Time{}, nil
+	}
+
+	t, err := time.Parse(time.RFC3339, raw)
+	if err != nil {
+		return time.Time{}, invalidParam(name, "invalid %s %q: must be an RFC 3339 time such as 2024-05-01T00:00:00Z", name, raw)
+	}
+	return t, nil
+}
+
+// parseDurationParam parses the named parameter as a positive duration, either in Go syntax such as
+// 90m or 3h, or as a number of seconds. It returns zero if the parameter is not set.
+func parseDurationParam(values url.Values, name string) (time.Duration, error) {
+	raw := values.Get(name)
+	if raw == "" {
+		return 0, nil
+	}
+
+	duration, err := time.ParseDuration(raw)
+	if seconds, atoiErr := strconv.ParseInt(raw, 10, 64); atoiErr == nil {
+		duration, err = time.Duration(seconds)*time.Second, nil
+	}
+	if err != nil {
+		return 0, invalidParam(name, "invalid %s %q: must be a duration such as 90m or 3h, or a number of seconds", name, raw)
+	}
+	if duration <= 0 {
+		return 0, invalidParam(name, "invalid %s %q: must be positive", name, raw)
+	}
+	return duration, nil
+}
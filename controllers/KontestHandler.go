@@ -1,21 +1,68 @@
 package controllers
 
 import (
+	"context"
 	"encoding/json"
-	"kontest-api/utils"
+	"github.com/google/uuid"
+	"kontest-api/model"
+	"kontest-api/service"
 	"kontest-api/utils/enums"
 	"net/http"
-	"strconv"
 	"strings"
+	"time"
 )
 
-func GetAllKontests(w http.ResponseWriter, r *http.Request) {
-	kontestService := utils.GetDependencies().KontestService
+// KontestService is the part of the service layer used by the handlers
+type KontestService interface {
+	Query(ctx context.Context, query service.KontestQuery) (*service.KontestQueryResult, error)
+	FindByID(ctx context.Context, id uuid.UUID) (*model.KontestModel, error)
+	Status() service.RefreshStatus
+}
+
+// Refresher starts a refresh of every enabled source
+type Refresher interface {
+	Trigger() error
+}
+
+// KontestHandler serves the contest endpoints
+type KontestHandler struct {
+	kontestService KontestService
+	refresher      Refresher
+
+	// pingDatabase checks the database, it is nil if contests are only kept in memory
+	pingDatabase func(ctx context.Context) error
+
+	// maxPerPage is the largest page size a client may request
+	maxPerPage int
+}
+
+// NewKontestHandler creates a new KontestHandler. pingDatabase may be nil if there is no database.
+func NewKontestHandler(kontestService KontestService, refresher Refresher, pingDatabase func(ctx context.Context) error, maxPerPage int) *KontestHandler {
+	return &KontestHandler{
+		kontestService: kontestService,
+		refresher:      refresher,
+		pingDatabase:   pingDatabase,
+		maxPerPage:     maxPerPage,
+	}
+}
+
+// kontestListResponse is the envelope of a page of contests
+type kontestListResponse struct {
+	Contests   []kontestResponse `json:"contests"`
+	Total      int               `json:"total"` // Number of contests across all pages
+	PerPage    int               `json:"per_page"`
+	NextCursor *string           `json:"next_cursor"` // Null on the last page
+	PrevCursor *string           `json:"prev_cursor"` // Null on the first page
+}
 
+// GetAllKontests lists the contests matching the filters, one page at a time. Without cursor or
+// page, the first page is returned; the cursors of the response page through the same snapshot
+// of the contests even if they are refreshed in between. The page parameter is still accepted
+// but pages through the current contests.
+func (h *KontestHandler) GetAllKontests(w http.ResponseWriter, r *http.Request) {
 	// Parse query parameters
-	rawSites := r.URL.Query().Get("sites") // Get the sites parameter as a single string
-	pageStr := r.URL.Query().Get("page")
-	perPageStr := r.URL.Query().Get("per_page")
+	values := r.URL.Query()
+	rawSites := values.Get("sites") // Get the sites parameter as a single string
 
 	var siteList []string
 	if rawSites != "" {
@@ -23,56 +70,193 @@ func GetAllKontests(w http.ResponseWriter, r *http.Request) {
 		siteList = strings.Split(rawSites, ",")
 	}
 
-	// Convert page and perPage to integers
-	page, err := strconv.Atoi(pageStr)
-	if err != nil || page <= 0 {
-		http.Error(w, "Invalid page number", http.StatusBadRequest)
+	perPage, err := parsePerPage(values, h.maxPerPage)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	query := service.KontestQuery{
+		Sites: siteList,
+		Limit: perPage,
+	}
+	if err := parseKontestFilters(values, &query); err != nil {
+		writeError(w, r, err)
 		return
 	}
 
-	perPage := 10 // Default perPage
-	if perPageStr != "" {
-		perPage, err = strconv.Atoi(perPageStr)
-		if err != nil || perPage <= 0 {
-			http.Error(w, "Invalid per_page number", http.StatusBadRequest)
+	fingerprint := filtersFingerprint(values, perPage)
+	switch {
+	case values.Has("cursor") && values.Has("page"):
+		writeError(w, r, invalidParam("cursor", "cursor and page cannot be combined"))
+		return
+
+	case values.Has("cursor"):
+		cursor, err := decodeKontestCursor(values.Get("cursor"))
+		if err != nil {
+			writeError(w, r, err)
 			return
 		}
-	}
+		if cursor.Filters != fingerprint {
+			writeError(w, r, invalidParam("cursor", "cursor belongs to a listing with different filters, sort or per_page"))
+			return
+		}
+		query.Version, query.At, query.Offset = cursor.Version, time.Unix(0, cursor.At), cursor.Offset
 
-	var contests []map[string]string
-	if len(siteList) == 0 {
-		// If there are no specific sites, get all contests
-		contests, err = kontestService.GetContests(page, perPage)
-	} else {
-		// If there are specific sites, fetch contests for those sites
-		contests, err = kontestService.GetContestsOfSpecificSites(siteList, page, perPage)
+	case values.Has("page"):
+		page, err := parsePage(values)
+		if err != nil {
+			writeError(w, r, err)
+			return
+		}
+		query.Offset = (page - 1) * perPage
 	}
 
+	result, err := h.kontestService.Query(r.Context(), query)
 	if err != nil {
-		http.Error(w, "Failed to get contests: "+err.Error(), http.StatusInternalServerError)
+		writeError(w, r, err)
 		return
 	}
 
+	// Convert the contests to their JSON representation
+	response := kontestListResponse{
+		Contests: make([]kontestResponse, len(result.Contests)),
+		Total:    result.Total,
+		PerPage:  perPage,
+	}
+	for i := range result.Contests {
+		response.Contests[i] = newKontestResponse(&result.Contests[i], result.Now)
+	}
+
+	cursorAt := func(offset int) *string {
+		cursor := kontestCursor{Version: result.Version, At: result.Now.UnixNano(), Offset: offset, Filters: fingerprint}.encode()
+		return &cursor
+	}
+	if query.Offset+perPage < result.Total {
+		response.NextCursor = cursorAt(query.Offset + perPage)
+	}
+	if query.Offset > 0 {
+		response.PrevCursor = cursorAt(max(query.Offset-perPage, 0))
+	}
+	setPaginationHeaders(w, r, result.Total, response.NextCursor, response.PrevCursor)
+
 	// Return the contests in the response
 	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(contests)
+	json.NewEncoder(w).Encode(response)
 }
 
-func PurgeMetadata(w http.ResponseWriter, r *http.Request) {
-	kontestService := utils.GetDependencies().KontestService
+// GetKontest returns the contest with the ID in the path, with its status at the time of the request
+func (h *KontestHandler) GetKontest(w http.ResponseWriter, r *http.Request) {
+	// IDs that are not UUIDs cannot belong to any contest
+	id, err := uuid.Parse(r.PathValue("id"))
+	if err != nil {
+		writeError(w, r, service.ErrKontestNotFound)
+		return
+	}
+
+	contest, err := h.kontestService.FindByID(r.Context(), id)
+	if err != nil {
+		writeError(w, r, err)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(newKontestResponse(contest, time.Now()))
+}
 
-	kontestService.PurgeMetadata()
+func (h *KontestHandler) PurgeMetadata(w http.ResponseWriter, r *http.Request) {
+	// Refresh every source right away instead of waiting for their intervals to pass
+	if err := h.refresher.Trigger(); err != nil {
+		writeError(w, r, err)
+		return
+	}
 
+	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(map[string]string{"message": "Metadata purged successfully"})
 }
 
-func HealthCheck(writer http.ResponseWriter, request *http.Request) {
+func (h *KontestHandler) HealthCheck(writer http.ResponseWriter, request *http.Request) {
+	// The last good contests are still served when refreshes fail, so a degraded service stays up
 	writer.WriteHeader(http.StatusOK)
+	if h.databaseState(request) == "down" {
+		writer.Write([]byte("Service is degraded: database is down, see /status for details"))
+		return
+	}
+	if !h.kontestService.Status().Healthy() {
+		writer.Write([]byte("Service is degraded, see /status for details"))
+		return
+	}
 	writer.Write([]byte("Service is healthy"))
 }
 
-func GetSupportedSites(writer http.ResponseWriter, request *http.Request) {
+func (h *KontestHandler) GetStatus(writer http.ResponseWriter, request *http.Request) {
+	status := struct {
+		Database string `json:"database"`
+		service.RefreshStatus
+	}{
+		Database:      h.databaseState(request),
+		RefreshStatus: h.kontestService.Status(),
+	}
+
+	writer.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(writer).Encode(status)
+}
+
+func (h *KontestHandler) GetSupportedSites(writer http.ResponseWriter, request *http.Request) {
 	supportedSites := enums.GetAllAbbreviations()
 	writer.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(writer).Encode(supportedSites)
 }
+
+// databaseState reports whether the database is "up" or "down", or "memory" if there is no database
+func (h *KontestHandler) databaseState(request *http.Request) string {
+	if h.pingDatabase == nil {
+		return "memory"
+	}
+	if h.pingDatabase(request.Context()) != nil {
+		return "down"
+	}
+	return "up"
+}
+
+// kontestResponse is the JSON representation of a contest. Times are RFC 3339 strings.
+type kontestResponse struct {
+	ID               uuid.UUID           `json:"id"`
+	Name             string              `json:"name"`
+	URL              string              `json:"url"`
+	StartTime        string              `json:"start_time"`
+	EndTime          string              `json:"end_time"`
+	Location         string              `json:"location"`
+	Status           enums.ContestStatus `json:"status"` // Status at the time of the request
+	DurationSeconds  int64               `json:"duration_seconds"`
+	SiteAbbreviation string              `json:"site_abbreviation"`
+	Source           string              `json:"source"`
+	Provenance       map[string]string   `json:"provenance"` // Source that contributed each field
+	FirstSeenAt      string              `json:"first_seen_at"`
+	UpdatedAt        string              `json:"updated_at"`
+}
+
+// newKontestResponse converts a contest to its JSON representation, with the status at the given time
+func newKontestResponse(contest *model.KontestModel, now time.Time) kontestResponse {
+	// Contests stored before provenance was recorded have none
+	provenance := contest.Provenance
+	if provenance == nil {
+		provenance = map[string]string{}
+	}
+
+	return kontestResponse{
+		ID:               contest.ID,
+		Name:             contest.Name,
+		URL:              contest.URL,
+		StartTime:        contest.StartTime.Format(time.RFC3339),
+		EndTime:          contest.EndTime.Format(time.RFC3339),
+		Location:         contest.Location,
+		Status:           contest.StatusAt(now),
+		DurationSeconds:  contest.DurationSeconds(),
+		SiteAbbreviation: contest.SiteAbbreviation,
+		Source:           contest.Source,
+		Provenance:       provenance,
+		FirstSeenAt:      contest.FirstSeenAt.Format(time.RFC3339),
+		UpdatedAt:        contest.UpdatedAt.Format(time.RFC3339),
+	}
+}
@@ -0,0 +1,113 @@
+package controllers
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// defaultPerPage is the page size used when per_page is not given
+const defaultPerPage = 10
+
+// kontestCursor points at a page of a contest listing. It is tied to the snapshot the listing was
+// read from and to the time the statuses were evaluated at, so paging through it is not affected
+// by refreshes. Clients receive it as an opaque string.
+type kontestCursor struct {
+	Version uint64 `json:"v"` // Version of the snapshot
+	At      int64  `json:"t"` // Time the statuses are evaluated at, in Unix nanoseconds
+	Offset  int    `json:"o"` // Position of the first contest of the page
+	Filters uint64 `json:"f"` // Fingerprint of the filters and page size of the listing
+}
+
+// encode returns the cursor as an opaque string
+func (c kontestCursor) encode() string {
+	data, _ := json.Marshal(c)
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// decodeKontestCursor parses a cursor returned by encode
+func decodeKontestCursor(raw string) (kontestCursor, error) {
+	var cursor kontestCursor
+	data, err := base64.RawURLEncoding.DecodeString(raw)
+	if err == nil {
+		err = json.Unmarshal(data, &cursor)
+	}
+	if err != nil || cursor.Version == 0 || cursor.Offset < 0 {
+		return kontestCursor{}, invalidParam("cursor", "invalid cursor: use the next_cursor or prev_cursor of a previous response")
+	}
+	return cursor, nil
+}
+
+// filtersFingerprint hashes the query parameters that select and order the contests, and the page
+// size, so that a cursor cannot be used with a listing different from the one it was created for.
+func filtersFingerprint(values url.Values, perPage int) uint64 {
+	filters := url.Values{}
+	for name, value := range values {
+		filters[name] = value
+	}
+	filters.Del("cursor")
+	filters.Del("page")
+	filters.Set("per_page", strconv.Itoa(perPage))
+
+	hash := fnv.New64a()
+	hash.Write([]byte(filters.Encode())) // Encode sorts the parameters by name
+	return hash.Sum64()
+}
+
+// parsePerPage reads the per_page parameter, which must be between 1 and maxPerPage
+func parsePerPage(values url.Values, maxPerPage int) (int, error) {
+	raw := values.Get("per_page")
+	if raw == "" {
+		return min(defaultPerPage, maxPerPage), nil
+	}
+
+	perPage, err := strconv.Atoi(raw)
+	if err != nil || perPage <= 0 || perPage > maxPerPage {
+		return 0, invalidParam("per_page", "invalid per_page %q: must be a number between 1 and %d", raw, maxPerPage)
+	}
+	return perPage, nil
+}
+
+// parsePage reads the page parameter, which must be a positive number
+func parsePage(values url.Values) (int, error) {
+	raw := values.Get("page")
+	page, err := strconv.Atoi(raw)
+	if err != nil || page <= 0 {
+		return 0, invalidParam("page", "invalid page %q: must be a positive number", raw)
+	}
+	return page, nil
+}
+
+// setPaginationHeaders sets the X-Total-Count header and the RFC 5988 Link header with the
+// first page and, if there are any, the next and previous pages.
+func setPaginationHeaders(w http.ResponseWriter, r *http.Request, total int, next, prev *string) {
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
+
+	links := []string{fmt.Sprintf(`<%s>; rel="first"`, pageURL(r, ""))}
+	if next != nil {
+		links = append(links, fmt.Sprintf(`<%s>; rel="next"`, pageURL(r, *next)))
+	}
+	if prev != nil {
+		links = append(links, fmt.Sprintf(`<%s>; rel="prev"`, pageURL(r, *prev)))
+	}
+	w.Header().Set("Link", strings.Join(links, ", "))
+}
+
+// pageURL returns the URL of the request pointing at the page of the cursor, or at the first
+// page of the current contests if the cursor is empty
+func pageURL(r *http.Request, cursor string) string {
+	values := r.URL.Query()
+	values.Del("page")
+	values.Del("cursor")
+	if cursor != "" {
+		values.Set("cursor", cursor)
+	}
+
+	pageURL := url.URL{Path: r.URL.Path, RawQuery: values.Encode()}
+	return pageURL.String()
+}
@@ -0,0 +1,223 @@
+package database
+
+import (
+	"embed"
+	"fmt"
+	"gorm.io/gorm"
+	"io/fs"
+	"path"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// migrationFiles holds one directory of migrations per dialect, e.g. migrations/postgres
+//
+//go:embed migrations/*/*.sql
+var migrationFiles embed.FS
+
+// Migration is a single versioned schema change with its up and down SQL.
+type Migration struct {
+	Version int64
+	Name    string
+	UpSQL   string
+	DownSQL string
+}
+
+// SchemaMigration is a record in the schema_migrations table
+type SchemaMigration struct {
+	Version   int64     `gorm:"primaryKey;autoIncrement:false"`
+	Name      string    `gorm:"not null"`
+	AppliedAt time.Time `gorm:"not null"`
+}
+
+// TableName sets the table name for the SchemaMigration struct
+func (m *SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
+
+// MigrationState describes whether a migration has been applied.
+type MigrationState struct {
+	Migration
+	AppliedAt *time.Time
+}
+
+// Migrator applies and reverts the migrations embedded in the binary.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []Migration
+}
+
+// NewMigrator creates a new Migrator for the given database using the embedded migrations
+// written for its dialect.
+func NewMigrator(db *gorm.DB) (*Migrator, error) {
+	migrations, err := loadMigrations(migrationFiles, db.Dialector.Name())
+	if err != nil {
+		return nil, err
+	}
+	return &Migrator{db: db, migrations: migrations}, nil
+}
+
+// loadMigrations reads the migration files of the dialect, named "<version>_<name>.up.sql" and
+// "<version>_<name>.down.sql", and returns them ordered by version.
+func loadMigrations(files fs.FS, dialect string) ([]Migration, error) {
+	entries, err := fs.Glob(files, "migrations/"+dialect+"/*.sql")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list migrations: %w", err)
+	}
+	if len(entries) == 0 {
+		return nil, fmt.Errorf("no migrations found for the %s dialect", dialect)
+	}
+
+	byVersion := make(map[int64]*Migration)
+	for _, entry := range entries {
+		base := path.Base(entry)
+
+		var direction string
+		switch {
+		case strings.HasSuffix(base, ".up.sql"):
+			direction = "up"
+		case strings.HasSuffix(base, ".down.sql"):
+			direction = "down"
+		default:
+			return nil, fmt.Errorf("migration %s must end in .up.sql or .down.sql", base)
+		}
+
+		versionPart, name, found := strings.Cut(strings.TrimSuffix(base, "."+direction+".sql"), "_")
+		if !found {
+			return nil, fmt.Errorf("migration %s must be named <version>_<name>", base)
+		}
+		version, err := strconv.ParseInt(versionPart, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("migration %s has an invalid version: %w", base, err)
+		}
+
+		content, err := fs.ReadFile(files, entry)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read migration %s: %w", base, err)
+		}
+
+		migration, ok := byVersion[version]
+		if !ok {
+			migration = &Migration{Version: version, Name: name}
+			byVersion[version] = migration
+		} else if migration.Name != name {
+			return nil, fmt.Errorf("migrations %s and %s share version %d", migration.Name, name, version)
+		}
+
+		if direction == "up" {
+			migration.UpSQL = string(content)
+		} else {
+			migration.DownSQL = string(content)
+		}
+	}
+
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, migration := range byVersion {
+		if migration.UpSQL == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up migration", migration.Version, migration.Name)
+		}
+		migrations = append(migrations, *migration)
+	}
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+	return migrations, nil
+}
+
+// applied returns the applied migrations keyed by version, creating the schema_migrations table if needed.
+func (m *Migrator) applied() (map[int64]SchemaMigration, error) {
+	if err := m.db.AutoMigrate(&SchemaMigration{}); err != nil {
+		return nil, fmt.Errorf("failed to create schema_migrations table: %w", err)
+	}
+
+	var records []SchemaMigration
+	if err := m.db.Find(&records).Error; err != nil {
+		return nil, fmt.Errorf("failed to read schema_migrations: %w", err)
+	}
+
+	applied := make(map[int64]SchemaMigration, len(records))
+	for _, record := range records {
+		applied[record.Version] = record
+	}
+	return applied, nil
+}
+
+// Up applies every pending migration in version order. Each migration runs in its own
+// transaction together with its schema_migrations record. It returns the applied migrations.
+func (m *Migrator) Up() ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for _, migration := range m.migrations {
+		if _, ok := applied[migration.Version]; ok {
+			continue
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.UpSQL).Error; err != nil {
+				return err
+			}
+			record := SchemaMigration{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}
+			return tx.Create(&record).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("failed to apply migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Down reverts the given number of most recently applied migrations, newest first.
+// It returns the reverted migrations.
+func (m *Migrator) Down(steps int) ([]Migration, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	var done []Migration
+	for i := len(m.migrations) - 1; i >= 0 && len(done) < steps; i-- {
+		migration := m.migrations[i]
+		if _, ok := applied[migration.Version]; !ok {
+			continue
+		}
+		if migration.DownSQL == "" {
+			return done, fmt.Errorf("migration %d_%s has no down migration", migration.Version, migration.Name)
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if err := tx.Exec(migration.DownSQL).Error; err != nil {
+				return err
+			}
+			return tx.Delete(&SchemaMigration{}, migration.Version).Error
+		})
+		if err != nil {
+			return done, fmt.Errorf("failed to revert migration %d_%s: %w", migration.Version, migration.Name, err)
+		}
+		done = append(done, migration)
+	}
+	return done, nil
+}
+
+// Status returns every known migration along with the time it was applied, if it was.
+func (m *Migrator) Status() ([]MigrationState, error) {
+	applied, err := m.applied()
+	if err != nil {
+		return nil, err
+	}
+
+	states := make([]MigrationState, len(m.migrations))
+	for i, migration := range m.migrations {
+		states[i] = MigrationState{Migration: migration}
+		if record, ok := applied[migration.Version]; ok {
+			states[i].AppliedAt = &record.AppliedAt
+		}
+	}
+	return states, nil
+}
@@ -1,39 +1,121 @@
 package database
 
 import (
+	"context"
 	"fmt"
+	"github.com/glebarez/sqlite"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 	"gorm.io/gorm/logger"
+	"kontest-api/config"
+	"log"
+	"time"
 )
 
-// db is a package-level variable to hold the database connection
-var db *gorm.DB
-
-// Connect initializes the database connection with the provided parameters
-func Connect(dbname, port, host, user, password, sslmode string) error {
-	// Create the Data Source Name (DSN)
-	var dsn string
-	if password == "" {
-		dsn = fmt.Sprintf("host=%s user=%s dbname=%s port=%s sslmode=%s",
-			host, user, dbname, port, sslmode)
-	} else {
-		dsn = fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-			host, user, password, dbname, port, sslmode)
+// sqlLogLevels maps the logging.sql_level setting to the GORM log level
+var sqlLogLevels = map[string]logger.LogLevel{
+	"silent": logger.Silent,
+	"error":  logger.Error,
+	"warn":   logger.Warn,
+	"info":   logger.Info,
+}
+
+// Open creates the database handle and its pool with the provided configuration. It does not
+// connect: connections are opened on first use and reopened by the pool if the database goes
+// away, so the handle stays valid while the database is unavailable. Use WaitForConnection to
+// check that the database can be reached.
+func Open(cfg config.DatabaseConfig, sqlLevel string) (*gorm.DB, error) {
+	dialector := postgres.Open(cfg.ConnectionString())
+	if cfg.Driver == "sqlite" {
+		dialector = sqlite.Open(cfg.ConnectionString())
 	}
 
-	var err error
-	//db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{})
-	db, err = gorm.Open(postgres.Open(dsn), &gorm.Config{
-		Logger: logger.Default.LogMode(logger.Info), // Enable detailed logging
+	db, err := gorm.Open(dialector, &gorm.Config{
+		Logger:               logger.Default.LogMode(sqlLogLevels[sqlLevel]),
+		DisableAutomaticPing: true,
 	})
 	if err != nil {
-		return fmt.Errorf("failed to connect to database: %w", err)
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, fmt.Errorf("failed to configure database pool: %w", err)
+	}
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime.Std())
+
+	return db, nil
+}
+
+// WaitForConnection pings the database up to cfg.ConnectRetries additional times, doubling the
+// delay between attempts starting at cfg.ConnectRetryDelay. Every ping is cancelled after
+// cfg.QueryTimeout. It returns the last error if every attempt fails, or the context's error
+// if it is cancelled while waiting.
+func WaitForConnection(ctx context.Context, db *gorm.DB, cfg config.DatabaseConfig) error {
+	delay := cfg.ConnectRetryDelay.Std()
+
+	var err error
+	for attempt := 0; attempt <= cfg.ConnectRetries; attempt++ {
+		if attempt > 0 {
+			log.Printf("Unable to connect to database, retrying in %s: %v", delay, err)
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(delay):
+			}
+			delay *= 2
+		}
+
+		if err = pingWithTimeout(ctx, db, cfg.QueryTimeout.Std()); err == nil {
+			return nil
+		}
+	}
+	return fmt.Errorf("giving up after %d attempts: %w", cfg.ConnectRetries+1, err)
+}
+
+// KeepConnecting pings the database every cfg.ConnectRetryDelay until it responds or the context
+// is cancelled. It is used in degraded mode to report when the database becomes available.
+func KeepConnecting(ctx context.Context, db *gorm.DB, cfg config.DatabaseConfig) {
+	ticker := time.NewTicker(cfg.ConnectRetryDelay.Std())
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := pingWithTimeout(ctx, db, cfg.QueryTimeout.Std()); err != nil {
+				log.Printf("Database is still unavailable: %v", err)
+				continue
+			}
+			log.Println("Connected to database, leaving degraded mode.")
+			return
+		}
+	}
+}
+
+// Ping checks that the database is responding
+func Ping(ctx context.Context, db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
+	}
+	return sqlDB.PingContext(ctx)
+}
+
+// Close closes the database pool, waiting for the queries that are running to finish
+func Close(db *gorm.DB) error {
+	sqlDB, err := db.DB()
+	if err != nil {
+		return err
 	}
-	return nil
+	return sqlDB.Close()
 }
 
-// GetDB returns the current database connection
-func GetDB() *gorm.DB {
-	return db
+func pingWithTimeout(ctx context.Context, db *gorm.DB, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	return Ping(ctx, db)
 }
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS kontests_metadata;
+DROP TABLE IF EXISTS kontests;
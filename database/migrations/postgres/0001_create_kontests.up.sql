@@ -0,0 +1,43 @@
+-- Baseline schema for the kontests and kontests_metadata tables.
+-- Written so that it can also be applied to databases whose tables were created by hand.
+
+-- KontestModel relies on uuid_generate_v7() for its default ID. Provide a plpgsql
+-- implementation unless the function already exists, e.g. from the pg_uuidv7 extension.
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_proc WHERE proname = 'uuid_generate_v7') THEN
+        EXECUTE $fn$
+            CREATE FUNCTION uuid_generate_v7() RETURNS uuid AS $body$
+            BEGIN
+                -- Overlay the millisecond Unix timestamp on a random v4 UUID and switch the version to 7
+                RETURN encode(
+                    set_bit(
+                        set_bit(
+                            overlay(uuid_send(gen_random_uuid())
+                                    placing substring(int8send(floor(extract(epoch FROM clock_timestamp()) * 1000)::bigint) FROM 3)
+                                    FROM 1 FOR 6),
+                            52, 1),
+                        53, 1),
+                    'hex')::uuid;
+            END
+            $body$ LANGUAGE plpgsql VOLATILE;
+        $fn$;
+    END IF;
+END
+$$;
+
+CREATE TABLE IF NOT EXISTS kontests (
+    id                uuid PRIMARY KEY DEFAULT uuid_generate_v7(),
+    name              text NOT NULL,
+    url               text,
+    start_time        text,
+    end_time          text,
+    location          text,
+    status            text,
+    site_abbreviation text
+);
+
+CREATE TABLE IF NOT EXISTS kontests_metadata (
+    id              text PRIMARY KEY,
+    last_updated_at timestamptz
+);
@@ -0,0 +1,2 @@
+ALTER TABLE kontests DROP COLUMN IF EXISTS provenance;
+ALTER TABLE kontests DROP COLUMN IF EXISTS source;
@@ -0,0 +1,4 @@
+-- Contests merged from several sources record the source they were taken from and the source
+-- that contributed each field.
+ALTER TABLE kontests ADD COLUMN IF NOT EXISTS source     text;
+ALTER TABLE kontests ADD COLUMN IF NOT EXISTS provenance jsonb;
@@ -0,0 +1,5 @@
+DROP INDEX IF EXISTS idx_kontests_natural_key;
+ALTER TABLE kontests DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE kontests DROP COLUMN IF EXISTS first_seen_at;
+ALTER TABLE kontests DROP COLUMN IF EXISTS natural_key;
+ALTER TABLE kontests DROP COLUMN IF EXISTS source_contest_id;
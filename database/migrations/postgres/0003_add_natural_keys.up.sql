@@ -0,0 +1,10 @@
+-- Contests are upserted by a stable natural key so that they keep their ID across refreshes.
+ALTER TABLE kontests ADD COLUMN IF NOT EXISTS source_contest_id text;
+ALTER TABLE kontests ADD COLUMN IF NOT EXISTS natural_key       text;
+ALTER TABLE kontests ADD COLUMN IF NOT EXISTS first_seen_at     timestamptz;
+ALTER TABLE kontests ADD COLUMN IF NOT EXISTS updated_at        timestamptz;
+
+-- Rows stored before natural keys existed are replaced on the next refresh
+UPDATE kontests SET natural_key = id::text WHERE natural_key IS NULL;
+ALTER TABLE kontests ALTER COLUMN natural_key SET NOT NULL;
+CREATE UNIQUE INDEX IF NOT EXISTS idx_kontests_natural_key ON kontests (natural_key);
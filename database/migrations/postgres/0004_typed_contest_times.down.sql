@@ -0,0 +1,7 @@
+ALTER TABLE kontests ADD COLUMN IF NOT EXISTS status text NOT NULL DEFAULT '';
+
+ALTER TABLE kontests
+    ALTER COLUMN start_time TYPE text
+        USING to_char(start_time AT TIME ZONE 'UTC', 'FMMonth FMDD, YYYY HH24:MI:SS'),
+    ALTER COLUMN end_time TYPE text
+        USING to_char(end_time AT TIME ZONE 'UTC', 'FMMonth FMDD, YYYY HH24:MI:SS');
@@ -0,0 +1,10 @@
+-- Store contest times as timestamptz instead of "January 2, 2006 15:04:05" strings.
+-- The strings are UTC times; empty strings become NULL.
+ALTER TABLE kontests
+    ALTER COLUMN start_time TYPE timestamptz
+        USING to_timestamp(NULLIF(start_time, ''), 'FMMonth FMDD, YYYY HH24:MI:SS')::timestamp AT TIME ZONE 'UTC',
+    ALTER COLUMN end_time TYPE timestamptz
+        USING to_timestamp(NULLIF(end_time, ''), 'FMMonth FMDD, YYYY HH24:MI:SS')::timestamp AT TIME ZONE 'UTC';
+
+-- The status is computed from the contest times when contests are read
+ALTER TABLE kontests DROP COLUMN IF EXISTS status;
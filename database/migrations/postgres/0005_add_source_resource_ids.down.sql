@@ -0,0 +1 @@
+ALTER TABLE kontests DROP COLUMN IF EXISTS source_resource_id;
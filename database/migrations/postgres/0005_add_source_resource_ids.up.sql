@@ -0,0 +1,2 @@
+-- Contests fetched from the clist API record the clist ID of their site.
+ALTER TABLE kontests ADD COLUMN IF NOT EXISTS source_resource_id text;
@@ -0,0 +1,23 @@
+-- Schema for SQLite databases. IDs are always generated by the application.
+CREATE TABLE kontests (
+    id                TEXT PRIMARY KEY,
+    name              TEXT NOT NULL,
+    url               TEXT,
+    start_time        DATETIME,
+    end_time          DATETIME,
+    location          TEXT,
+    site_abbreviation TEXT,
+    source            TEXT,
+    source_contest_id TEXT,
+    provenance        TEXT,
+    natural_key       TEXT NOT NULL,
+    first_seen_at     DATETIME,
+    updated_at        DATETIME
+);
+
+CREATE UNIQUE INDEX idx_kontests_natural_key ON kontests (natural_key);
+
+CREATE TABLE kontests_metadata (
+    id              TEXT PRIMARY KEY,
+    last_updated_at DATETIME
+);
@@ -0,0 +1 @@
+ALTER TABLE kontests DROP COLUMN source_resource_id;
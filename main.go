@@ -1,59 +1,176 @@
 package main
 
 import (
+	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"gorm.io/gorm"
+	"kontest-api/app"
+	"kontest-api/config"
 	"kontest-api/database"
-	"kontest-api/middleware"
-	"kontest-api/routes"
-	"kontest-api/utils"
+	"log"
 	"net/http"
 	"os"
+	"os/signal"
+	"syscall"
+	"time"
 )
 
+// defaultConfigPath is loaded when no config file is given and it exists
+const defaultConfigPath = "config.yaml"
+
 func main() {
-	initalizeDatabase("kontest", "5432", "localhost", "postgres", "postgres", "disable")
+	configPath := flag.String("config", os.Getenv("KONTEST_API_CONFIG"), "path to the YAML config file")
+	flag.Parse()
+	args := flag.Args()
 
-	utils.InitializeDependencies()
+	cfg, err := loadConfig(*configPath)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
 
-	router := http.NewServeMux()
+	if len(args) > 0 && args[0] == "config" {
+		os.Exit(runConfig(cfg, args[1:]))
+	}
 
-	routes.RegisterRoutes(router)
+	// Stop on SIGINT or SIGTERM: the context is cancelled, which also stops the background work
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
 
-	stack := middleware.CreateStack(
-		middleware.Logging,
-	)
+	// Migrations cannot run without a database, so they never start in degraded mode
+	isMigrate := len(args) > 0 && args[0] == "migrate"
+	if isMigrate && cfg.Database.Driver == "memory" {
+		fmt.Fprintln(os.Stderr, "The memory driver has no schema to migrate")
+		os.Exit(2)
+	}
 
-	port := os.Getenv("KONTEST_API_SERVER_PORT")
-	if port == "" {
-		port = "5151"
+	var db *gorm.DB
+	connected := false
+	if cfg.Database.Driver != "memory" {
+		var ok bool
+		db, connected, ok = initalizeDatabase(ctx, cfg, !isMigrate && cfg.Database.OnUnavailable == "degraded")
+		if !ok {
+			os.Exit(1)
+		}
 	}
 
-	server := http.Server{
-		Addr:    ":" + port,    // Use the field name Addr for the address
-		Handler: stack(router), // Use the field name Handler for the router
+	if isMigrate {
+		os.Exit(runMigrate(db, args[1:]))
 	}
 
-	fmt.Println("Server listening at port: " + port)
+	// Bring the schema up to date before anything reads from the database
+	if cfg.Database.AutoMigrate && connected {
+		if err := migrateOnStartup(db); err != nil {
+			fmt.Fprintf(os.Stderr, "Unable to migrate database: %v\n", err)
+			os.Exit(1)
+		}
+	}
 
-	err := server.ListenAndServe()
+	application, err := app.NewBuilder(cfg).WithDatabase(db).Build(ctx)
 	if err != nil {
-		fmt.Println(err)
-		return
+		fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+
+	// Refresh the contests in the background so requests only read the cache
+	application.RefreshScheduler.Start(ctx)
+
+	server := http.Server{
+		Addr:         ":" + cfg.Server.Port, // Use the field name Addr for the address
+		Handler:      application.Handler(), // Use the field name Handler for the router
+		ReadTimeout:  cfg.Server.ReadTimeout.Std(),
+		WriteTimeout: cfg.Server.WriteTimeout.Std(),
+		IdleTimeout:  cfg.Server.IdleTimeout.Std(),
+	}
+
+	fmt.Println("Server listening at port: " + cfg.Server.Port)
+
+	serverErr := make(chan error, 1)
+	go func() {
+		serverErr <- server.ListenAndServe()
+	}()
+
+	// A server that failed to start, e.g. because the port is taken, still stops the scheduler
+	// before exiting with an error
+	var listenErr error
+	select {
+	case err := <-serverErr:
+		if !errors.Is(err, http.ErrServerClosed) {
+			listenErr = err
+			fmt.Fprintf(os.Stderr, "Server failed: %v\n", err)
+		}
+		stop()
+	case <-ctx.Done():
+		log.Println("Shutting down, waiting for in-flight requests and refreshes to finish.")
+	}
+
+	if err := shutdown(&server, application, cfg.Server.ShutdownTimeout.Std()); err != nil {
+		fmt.Fprintf(os.Stderr, "Unclean shutdown: %v\n", err)
+		os.Exit(1)
 	}
+	if listenErr != nil {
+		os.Exit(1)
+	}
+	log.Println("Server stopped.")
+}
+
+// shutdown stops accepting connections and drains the in-flight requests, then stops the
+// application, all within the given timeout. The application's context must already be cancelled.
+func shutdown(server *http.Server, application *app.Application, timeout time.Duration) error {
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	var errs []error
+	if err := server.Shutdown(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("failed to drain requests: %w", err))
+	}
+	if err := application.Close(ctx); err != nil {
+		errs = append(errs, err)
+	}
+	return errors.Join(errs...)
+}
+
+// loadConfig loads the configuration from the given file, falling back to config.yaml
+// in the working directory if no file is given and it exists
+func loadConfig(path string) (*config.Config, error) {
+	if path == "" {
+		if _, err := os.Stat(defaultConfigPath); err == nil {
+			path = defaultConfigPath
+		}
+	}
+	return config.Load(path)
 }
 
-// Initialize the database connection with default values
-func initalizeDatabase(
-	dbname string,
-	dbPort string,
-	dbHost string,
-	user string,
-	password string,
-	sslmode string,
-) {
-
-	if dbErr := database.Connect(dbname, dbPort, dbHost, user, password, sslmode); dbErr != nil {
+// Initialize the database from the configuration and wait for it to respond, retrying with
+// backoff. It reports whether the database responded. If it still does not respond, it either
+// reports false for ok so the process exits, or with allowDegraded starts degraded mode and keeps
+// checking the database in the background.
+func initalizeDatabase(ctx context.Context, cfg *config.Config, allowDegraded bool) (db *gorm.DB, connected bool, ok bool) {
+	db, err := database.Open(cfg.Database, cfg.Logging.SQLLevel)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Unable to open database: %v\n", err)
+		return nil, false, false
+	}
+
+	dbErr := database.WaitForConnection(ctx, db, cfg.Database)
+	if dbErr == nil {
+		return db, true, true
+	}
+	if ctx.Err() != nil {
+		fmt.Fprintln(os.Stderr, "Interrupted while connecting to database")
+		return nil, false, false
+	}
+
+	if !allowDegraded {
 		fmt.Fprintf(os.Stderr, "Unable to connect to database: %v\n", dbErr)
-		return
+		fmt.Fprintln(os.Stderr, "Check the database settings shown by `kontest-api config print`, or set database.on_unavailable to \"degraded\" to start without it.")
+		return nil, false, false
 	}
+
+	log.Printf("Unable to connect to database, starting in degraded mode: %v", dbErr)
+	log.Println("Contests are served from memory until the database becomes available; migrations are not applied.")
+	go database.KeepConnecting(ctx, db, cfg.Database)
+	return db, false, true
 }
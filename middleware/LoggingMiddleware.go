@@ -11,6 +11,6 @@ func Logging(next http.Handler) http.Handler {
 		start := time.Now()
 		next.ServeHTTP(w, r)
 
-		log.Println(r.Method, r.URL.Path, time.Since(start))
+		log.Println(r.Method, r.URL.Path, time.Since(start), GetRequestID(r.Context()))
 	})
 }
@@ -0,0 +1,49 @@
+package middleware
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"net/http"
+)
+
+// RequestIDHeader is the header carrying the ID of a request, in both the request and the response
+const RequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the length of request IDs accepted from clients
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// RequestID assigns every request an ID, reusing the one sent by the client if it is valid, and
+// returns it in the X-Request-ID response header so that errors can be traced back to their logs.
+func RequestID(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		id := r.Header.Get(RequestIDHeader)
+		if !isValidRequestID(id) {
+			id = uuid.NewString()
+		}
+
+		w.Header().Set(RequestIDHeader, id)
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id)))
+	})
+}
+
+// GetRequestID returns the ID assigned to the request by RequestID, or "" if there is none
+func GetRequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// isValidRequestID reports whether id is short and only made of visible ASCII characters,
+// so it can safely be echoed in headers and logs
+func isValidRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,98 @@
+package main
+
+import (
+	"fmt"
+	"gorm.io/gorm"
+	"kontest-api/database"
+	"os"
+	"strconv"
+)
+
+const migrateUsage = `Usage: kontest-api migrate <command>
+
+Commands:
+  up          Apply all pending migrations
+  down [n]    Revert the last n applied migrations (default 1)
+  status      List migrations and whether they have been applied`
+
+// runMigrate runs the migrate subcommand with the given arguments and returns the exit code
+func runMigrate(db *gorm.DB, args []string) int {
+	if len(args) == 0 {
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Failed to load migrations: %v\n", err)
+		return 1
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := migrator.Up()
+		for _, migration := range applied {
+			fmt.Printf("Applied %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		if len(applied) == 0 {
+			fmt.Println("No pending migrations")
+		}
+
+	case "down":
+		steps := 1
+		if len(args) > 1 {
+			steps, err = strconv.Atoi(args[1])
+			if err != nil || steps <= 0 {
+				fmt.Fprintf(os.Stderr, "Invalid number of migrations to revert: %s\n", args[1])
+				return 2
+			}
+		}
+
+		reverted, err := migrator.Down(steps)
+		for _, migration := range reverted {
+			fmt.Printf("Reverted %d_%s\n", migration.Version, migration.Name)
+		}
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+
+	case "status":
+		states, err := migrator.Status()
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+		for _, state := range states {
+			appliedAt := "pending"
+			if state.AppliedAt != nil {
+				appliedAt = "applied at " + state.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			fmt.Printf("%d_%s\t%s\n", state.Version, state.Name, appliedAt)
+		}
+
+	default:
+		fmt.Fprintln(os.Stderr, migrateUsage)
+		return 2
+	}
+
+	return 0
+}
+
+// migrateOnStartup applies pending migrations before the server starts
+func migrateOnStartup(db *gorm.DB) error {
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		return err
+	}
+
+	applied, err := migrator.Up()
+	for _, migration := range applied {
+		fmt.Printf("Applied migration %d_%s\n", migration.Version, migration.Name)
+	}
+	return err
+}
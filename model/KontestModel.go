@@ -4,21 +4,32 @@ import (
 	"github.com/google/uuid"
 	"gorm.io/gorm"
 	"kontest-api/utils/enums"
+	"strings"
+	"time"
 )
 
 // KontestModel represents a record in the kontests table
 type KontestModel struct {
 	ID               uuid.UUID `gorm:"type:uuid;primaryKey;default:uuid_generate_v7()" json:"id"` // Use UUID type as primary key
 	Name             string    `gorm:"not null" json:"name"`
-	URL              string    `json:"url"`               // Actual URL of the contest
-	StartTime        string    `json:"start_time"`        // Contest start time (stored as string)
-	EndTime          string    `json:"end_time"`          // Contest end time (stored as string)
-	Location         string    `json:"location"`          // Site on which the contest is hosted
-	Status           string    `json:"status"`            // Status of the contest
-	SiteAbbreviation string    `json:"site_abbreviation"` // Abbreviation of the contest site
+	URL              string    `json:"url"`                                // Actual URL of the contest
+	StartTime        time.Time `gorm:"type:timestamptz" json:"start_time"` // Contest start time
+	EndTime          time.Time `gorm:"type:timestamptz" json:"end_time"`   // Contest end time
+	Location         string    `json:"location"`                           // Site on which the contest is hosted
+	SiteAbbreviation string    `json:"site_abbreviation"`                  // Abbreviation of the contest site
+	Source           string    `json:"source"`                             // Name of the source the contest was taken from
+	SourceContestID  string    `json:"source_contest_id"`                  // Identifier of the contest within its source, if any
+	SourceResourceID string    `json:"source_resource_id"`                 // Identifier of the contest site in clist, if known
+	NaturalKey       string    `gorm:"uniqueIndex;not null" json:"-"`      // Stable identity used to keep the ID across refreshes
+	IdentityKeys     []string  `gorm:"-" json:"-"`                         // Natural keys of the contests merged into this one
+	FirstSeenAt      time.Time `json:"first_seen_at"`                      // Time the contest was first stored
+	UpdatedAt        time.Time `json:"updated_at"`                         // Time the contest was last stored
+
+	// Provenance maps each field name to the source that contributed its value
+	Provenance map[string]string `gorm:"serializer:json" json:"provenance"`
 }
 
-func NewKontestModel(name, url, startTime, endTime, location, status string) *KontestModel {
+func NewKontestModel(name, url string, startTime, endTime time.Time, location string) *KontestModel {
 	return &KontestModel{
 		ID:               uuid.New(),
 		Name:             name,
@@ -26,7 +37,6 @@ func NewKontestModel(name, url, startTime, endTime, location, status string) *Ko
 		StartTime:        startTime,
 		EndTime:          endTime,
 		Location:         location,
-		Status:           status,
 		SiteAbbreviation: enums.GetAbbreviation(location),
 	}
 }
@@ -36,8 +46,55 @@ func (k *KontestModel) TableName() string {
 	return "kontests"
 }
 
+// ComputeNaturalKey returns the identity of the contest as reported by its source. The contest URL
+// is preferred; contests without one fall back to their source and source contest ID, and finally
+// to their site, name and start time. Sources do not always agree on the URL of a contest, e.g.
+// clist links Codeforces rounds under /contests/ instead of /contest/, so a merged contest also
+// keeps the keys of every contest merged into it in IdentityKeys.
+func (k *KontestModel) ComputeNaturalKey() string {
+	if k.URL != "" {
+		return k.SiteAbbreviation + "|" + NormalizeURL(k.URL)
+	}
+	if k.SourceContestID != "" {
+		return k.Source + "|" + k.SourceContestID
+	}
+	return k.SiteAbbreviation + "|" + strings.ToLower(k.Name) + "|" + k.StartTime.UTC().Format(time.RFC3339)
+}
+
+// StatusAt computes the status of the contest at the given time
+func (k *KontestModel) StatusAt(now time.Time) enums.ContestStatus {
+	switch {
+	case now.Before(k.StartTime):
+		return enums.StatusUpcoming
+	case now.Before(k.EndTime):
+		return enums.StatusRunning
+	default:
+		return enums.StatusFinished
+	}
+}
+
+// DurationSeconds returns the length of the contest in seconds
+func (k *KontestModel) DurationSeconds() int64 {
+	return int64(k.EndTime.Sub(k.StartTime) / time.Second)
+}
+
+// NormalizeURL strips the scheme, a leading "www." and trailing slashes so equivalent URLs compare equal.
+func NormalizeURL(url string) string {
+	url = strings.ToLower(strings.TrimSpace(url))
+	url = strings.TrimPrefix(url, "https://")
+	url = strings.TrimPrefix(url, "http://")
+	url = strings.TrimPrefix(url, "www.")
+	return strings.TrimRight(url, "/")
+}
+
 // BeforeCreate is a GORM hook that runs before inserting a new record into the DB
 func (k *KontestModel) BeforeCreate(tx *gorm.DB) (err error) {
+	k.SetStorageDefaults()
+	return nil
+}
+
+// SetStorageDefaults fills in the fields that are derived or generated when a contest is stored
+func (k *KontestModel) SetStorageDefaults() {
 	// Generate a UUID if the ID is not already set
 	if k.ID == uuid.Nil {
 		k.ID = uuid.New()
@@ -45,5 +102,11 @@ func (k *KontestModel) BeforeCreate(tx *gorm.DB) (err error) {
 
 	// Generate the site abbreviation based on the location using the utility function
 	k.SiteAbbreviation = enums.GetAbbreviation(k.Location)
-	return nil
+
+	if k.NaturalKey == "" {
+		k.NaturalKey = k.ComputeNaturalKey()
+	}
+	if k.FirstSeenAt.IsZero() {
+		k.FirstSeenAt = time.Now()
+	}
 }
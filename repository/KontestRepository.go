@@ -2,11 +2,18 @@
 package repository
 
 import (
+	"context"
+	"github.com/google/uuid"
 	"kontest-api/model"
 )
 
 // KontestRepository defines methods for contest data operations.
 type KontestRepository interface {
-	Save(kontest model.KontestModel)
-	DeleteAll()
+	FindAll(ctx context.Context) ([]model.KontestModel, error)
+
+	// FindByID returns the contest with the given ID, or ErrNotFound if there is none.
+	FindByID(ctx context.Context, id uuid.UUID) (model.KontestModel, error)
+
+	// ReplaceAll atomically replaces every stored contest with the given contests.
+	ReplaceAll(ctx context.Context, kontests []model.KontestModel) error
 }
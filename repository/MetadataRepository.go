@@ -1,12 +1,13 @@
 package repository
 
 import (
+	"context"
 	"kontest-api/model"
 	"time"
 )
 
 // MetadataRepository defines methods for metadata operations.
 type MetadataRepository interface {
-	Save(metadata *model.Metadata)
-	GetLastUpdatedAt() time.Time
+	Save(ctx context.Context, metadata *model.Metadata) error
+	GetLastUpdatedAt(ctx context.Context) time.Time
 }
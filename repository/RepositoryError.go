@@ -0,0 +1,26 @@
+package repository
+
+import (
+	"errors"
+	"fmt"
+)
+
+// ErrUnavailable is wrapped by a RepositoryError when the underlying storage cannot be reached at all
+var ErrUnavailable = errors.New("storage is unavailable")
+
+// ErrNotFound is returned when the requested record is not stored
+var ErrNotFound = errors.New("record not found")
+
+// RepositoryError is returned when a repository operation fails.
+type RepositoryError struct {
+	Op  string // Operation that failed, e.g. "save contest"
+	Err error  // Underlying database error
+}
+
+func (e *RepositoryError) Error() string {
+	return fmt.Sprintf("repository: failed to %s: %v", e.Op, e.Err)
+}
+
+func (e *RepositoryError) Unwrap() error {
+	return e.Err
+}
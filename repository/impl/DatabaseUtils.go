@@ -0,0 +1,29 @@
+package impl
+
+import (
+	"context"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"gorm.io/gorm"
+	"kontest-api/repository"
+	"net"
+	"time"
+)
+
+// withTimeout binds the database to the context, cancelling the operation after timeout.
+// The returned function must be called to release the context once the operation is done.
+func withTimeout(ctx context.Context, db *gorm.DB, timeout time.Duration) (*gorm.DB, context.CancelFunc) {
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	return db.WithContext(ctx), cancel
+}
+
+// newRepositoryError wraps a database error in a repository.RepositoryError. Errors caused by the
+// database being unreachable also wrap repository.ErrUnavailable.
+func newRepositoryError(op string, err error) error {
+	var netErr net.Error
+	if errors.As(err, &netErr) || errors.Is(err, driver.ErrBadConn) {
+		err = fmt.Errorf("%w: %w", repository.ErrUnavailable, err)
+	}
+	return &repository.RepositoryError{Op: op, Err: err}
+}
@@ -0,0 +1,89 @@
+package impl
+
+import (
+	"context"
+	"github.com/google/uuid"
+	"kontest-api/model"
+	"kontest-api/repository"
+	"sync"
+	"time"
+)
+
+// InMemoryKontestRepository is an implementation of the KontestRepository interface that keeps
+// contests in memory. Nothing is persisted across restarts.
+type InMemoryKontestRepository struct {
+	mu       sync.RWMutex
+	kontests []model.KontestModel
+}
+
+// NewInMemoryKontestRepository creates a new, empty instance of InMemoryKontestRepository.
+func NewInMemoryKontestRepository() *InMemoryKontestRepository {
+	return &InMemoryKontestRepository{}
+}
+
+// FindAll returns a copy of all stored contests.
+func (repo *InMemoryKontestRepository) FindAll(ctx context.Context) ([]model.KontestModel, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	kontests := make([]model.KontestModel, len(repo.kontests))
+	copy(kontests, repo.kontests)
+	return kontests, nil
+}
+
+// FindByID returns the stored contest with the given ID.
+func (repo *InMemoryKontestRepository) FindByID(ctx context.Context, id uuid.UUID) (model.KontestModel, error) {
+	if err := ctx.Err(); err != nil {
+		return model.KontestModel{}, err
+	}
+
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	for _, kontest := range repo.kontests {
+		if kontest.ID == id {
+			return kontest, nil
+		}
+	}
+	return model.KontestModel{}, repository.ErrNotFound
+}
+
+// ReplaceAll replaces all stored contests, keeping the ID and first-seen time of contests whose
+// natural key is already stored. Like the database implementation, it updates the IDs and
+// first-seen times of the given contests in place.
+func (repo *InMemoryKontestRepository) ReplaceAll(ctx context.Context, kontests []model.KontestModel) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	existingByKey := make(map[string]model.KontestModel, len(repo.kontests))
+	for _, kontest := range repo.kontests {
+		existingByKey[kontest.NaturalKey] = kontest
+	}
+
+	now := time.Now()
+	for i := range kontests {
+		prepareForStorage(&kontests[i], now)
+		if stored, ok := existingByKey[kontests[i].NaturalKey]; ok {
+			kontests[i].ID = stored.ID
+			kontests[i].FirstSeenAt = stored.FirstSeenAt
+		}
+	}
+
+	repo.kontests = make([]model.KontestModel, len(kontests))
+	copy(repo.kontests, kontests)
+	return nil
+}
+
+// prepareForStorage fills in the fields the database would set when inserting a contest
+func prepareForStorage(kontest *model.KontestModel, now time.Time) {
+	kontest.SetStorageDefaults()
+	kontest.UpdatedAt = now
+}
@@ -0,0 +1,41 @@
+package impl
+
+import (
+	"context"
+	"kontest-api/model"
+	"sync"
+	"time"
+)
+
+// InMemoryMetadataRepository is an implementation of the MetadataRepository interface that keeps
+// the metadata in memory. Nothing is persisted across restarts.
+type InMemoryMetadataRepository struct {
+	mu       sync.RWMutex
+	metadata model.Metadata
+}
+
+// NewInMemoryMetadataRepository creates a new, empty instance of InMemoryMetadataRepository.
+func NewInMemoryMetadataRepository() *InMemoryMetadataRepository {
+	return &InMemoryMetadataRepository{}
+}
+
+// Save stores the metadata.
+func (repo *InMemoryMetadataRepository) Save(ctx context.Context, metadata *model.Metadata) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
+	repo.mu.Lock()
+	defer repo.mu.Unlock()
+
+	repo.metadata = *metadata
+	return nil
+}
+
+// GetLastUpdatedAt returns the last updated timestamp, or the zero time if nothing was saved yet.
+func (repo *InMemoryMetadataRepository) GetLastUpdatedAt(ctx context.Context) time.Time {
+	repo.mu.RLock()
+	defer repo.mu.RUnlock()
+
+	return repo.metadata.LastUpdatedAt
+}
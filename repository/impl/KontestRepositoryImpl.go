@@ -1,31 +1,119 @@
 package impl
 
 import (
-	"kontest-api/database"
+	"context"
+	"errors"
+	"github.com/google/uuid"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	"kontest-api/model"
-	"log"
+	"kontest-api/repository"
+	"time"
 )
 
+// insertBatchSize is the number of contests inserted per statement by ReplaceAll
+const insertBatchSize = 100
+
 // KontestRepositoryImpl is a concrete implementation of the KontestRepository interface.
-type KontestRepositoryImpl struct{}
+type KontestRepositoryImpl struct {
+	db           *gorm.DB
+	queryTimeout time.Duration
+}
+
+// NewKontestRepository creates a new instance of KontestRepositoryImpl using the given database.
+// Every operation is cancelled after queryTimeout.
+func NewKontestRepository(db *gorm.DB, queryTimeout time.Duration) *KontestRepositoryImpl {
+	return &KontestRepositoryImpl{db: db, queryTimeout: queryTimeout}
+}
 
-// NewKontestRepository creates a new instance of KontestRepositoryImpl.
-func NewKontestRepository() *KontestRepositoryImpl {
-	return &KontestRepositoryImpl{}
+// FindAll fetches all contests from the database.
+func (repo *KontestRepositoryImpl) FindAll(ctx context.Context) ([]model.KontestModel, error) {
+	db, cancel := withTimeout(ctx, repo.db, repo.queryTimeout)
+	defer cancel()
+
+	var kontests []model.KontestModel
+	if err := db.Find(&kontests).Error; err != nil {
+		return nil, newRepositoryError("find contests", err)
+	}
+	return kontests, nil
 }
 
-// Save saves a contest to the database.
-func (repo *KontestRepositoryImpl) Save(kontest model.KontestModel) {
-	if err := database.GetDB().Create(&kontest).Error; err != nil {
-		// Handle the error appropriately
-		log.Fatalf("Error saving contest: %v", err)
+// FindByID fetches the contest with the given ID from the database.
+func (repo *KontestRepositoryImpl) FindByID(ctx context.Context, id uuid.UUID) (model.KontestModel, error) {
+	db, cancel := withTimeout(ctx, repo.db, repo.queryTimeout)
+	defer cancel()
+
+	var kontest model.KontestModel
+	if err := db.First(&kontest, "id = ?", id).Error; err != nil {
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			return model.KontestModel{}, repository.ErrNotFound
+		}
+		return model.KontestModel{}, newRepositoryError("find contest", err)
 	}
+	return kontest, nil
 }
 
-// DeleteAll deletes all contests from the database.
-func (repo *KontestRepositoryImpl) DeleteAll() {
-	if err := database.GetDB().Unscoped().Delete(&model.KontestModel{}, "1=1").Error; err != nil {
-		// Handle the error appropriately
-		log.Fatalf("Error deleting contests: %v", err)
+// ReplaceAll replaces all contests in a single transaction. Contests are upserted by their natural
+// key so that a contest keeps its ID and first_seen_at across refreshes, and contests that are no
+// longer listed are pruned. The natural keys must be unique. The IDs and first-seen times of the
+// given contests are updated in place to match the stored rows. A failure leaves the previous
+// contests in place.
+func (repo *KontestRepositoryImpl) ReplaceAll(ctx context.Context, kontests []model.KontestModel) error {
+	db, cancel := withTimeout(ctx, repo.db, repo.queryTimeout)
+	defer cancel()
+
+	err := db.Transaction(func(tx *gorm.DB) error {
+		keys := make([]string, len(kontests))
+		for i := range kontests {
+			if kontests[i].NaturalKey == "" {
+				kontests[i].NaturalKey = kontests[i].ComputeNaturalKey()
+			}
+			keys[i] = kontests[i].NaturalKey
+		}
+
+		if len(kontests) == 0 {
+			return tx.Unscoped().Delete(&model.KontestModel{}, "1=1").Error
+		}
+
+		// Reuse the identity of contests that are already stored
+		var existing []model.KontestModel
+		if err := tx.Select("id", "natural_key", "first_seen_at").Where("natural_key IN ?", keys).Find(&existing).Error; err != nil {
+			return err
+		}
+
+		existingByKey := make(map[string]model.KontestModel, len(existing))
+		for _, kontest := range existing {
+			existingByKey[kontest.NaturalKey] = kontest
+		}
+
+		now := time.Now()
+		for i := range kontests {
+			if stored, ok := existingByKey[kontests[i].NaturalKey]; ok {
+				kontests[i].ID = stored.ID
+				kontests[i].FirstSeenAt = stored.FirstSeenAt
+			}
+			kontests[i].UpdatedAt = now
+		}
+
+		upsert := clause.OnConflict{
+			Columns:   []clause.Column{{Name: "natural_key"}},
+			DoUpdates: clause.AssignmentColumns(upsertColumns),
+		}
+		if err := tx.Clauses(upsert).CreateInBatches(kontests, insertBatchSize).Error; err != nil {
+			return err
+		}
+
+		return tx.Unscoped().Where("natural_key NOT IN ?", keys).Delete(&model.KontestModel{}).Error
+	})
+	if err != nil {
+		return newRepositoryError("replace contests", err)
 	}
+	return nil
+}
+
+// upsertColumns are the columns overwritten when an already stored contest is upserted.
+// The id and first_seen_at columns are deliberately left out to preserve the contest's identity.
+var upsertColumns = []string{
+	"name", "url", "start_time", "end_time", "location",
+	"site_abbreviation", "source", "source_contest_id", "source_resource_id", "provenance", "updated_at",
 }
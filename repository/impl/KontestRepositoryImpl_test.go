@@ -0,0 +1,111 @@
+package impl
+
+import (
+	"context"
+	"errors"
+	"kontest-api/config"
+	"kontest-api/database"
+	"kontest-api/model"
+	"kontest-api/repository"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// newSQLiteRepository creates a repository backed by a migrated SQLite database in a temporary directory
+func newSQLiteRepository(t *testing.T) *KontestRepositoryImpl {
+	t.Helper()
+	cfg := config.Default().Database
+	cfg.Driver = "sqlite"
+	cfg.SQLitePath = filepath.Join(t.TempDir(), "kontest.db")
+
+	db, err := database.Open(cfg, "silent")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() {
+		if sqlDB, err := db.DB(); err == nil {
+			sqlDB.Close()
+		}
+	})
+
+	migrator, err := database.NewMigrator(db)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := migrator.Up(); err != nil {
+		t.Fatalf("failed to apply migrations: %v", err)
+	}
+	return NewKontestRepository(db, 5*time.Second)
+}
+
+func newStoredKontest(name, url string, start time.Time, firstSeenAt time.Time) model.KontestModel {
+	kontest := model.NewKontestModel(name, url, start, start.Add(2*time.Hour), "codeforces.com")
+	kontest.Source = "codeforces"
+	kontest.Provenance = map[string]string{"name": "codeforces", "start_time": "codeforces"}
+	kontest.FirstSeenAt = firstSeenAt
+	return *kontest
+}
+
+func TestKontestRepositoryReplaceAllRoundTrip(t *testing.T) {
+	repo := newSQLiteRepository(t)
+	ctx := context.Background()
+	start := time.Date(2024, 7, 6, 12, 0, 0, 0, time.UTC)
+	firstSeenAt := time.Date(2024, 7, 1, 0, 0, 0, 0, time.UTC)
+
+	a := newStoredKontest("Codeforces Round 1", "https://codeforces.com/contest/1", start, firstSeenAt)
+	b := newStoredKontest("Codeforces Round 2", "https://codeforces.com/contest/2", start.Add(24*time.Hour), firstSeenAt)
+	if err := repo.ReplaceAll(ctx, []model.KontestModel{a, b}); err != nil {
+		t.Fatalf("first ReplaceAll failed: %v", err)
+	}
+
+	stored, err := repo.FindByID(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("FindByID failed: %v", err)
+	}
+	if stored.Name != a.Name || stored.URL != a.URL || stored.Source != "codeforces" || stored.SiteAbbreviation != a.SiteAbbreviation {
+		t.Errorf("stored contest = %+v, want %+v", stored, a)
+	}
+	if !stored.StartTime.Equal(a.StartTime) || !stored.EndTime.Equal(a.EndTime) || !stored.FirstSeenAt.Equal(firstSeenAt) {
+		t.Errorf("stored times = %v %v %v, want %v %v %v", stored.StartTime, stored.EndTime, stored.FirstSeenAt, a.StartTime, a.EndTime, firstSeenAt)
+	}
+	if stored.Provenance["start_time"] != "codeforces" || len(stored.Provenance) != 2 {
+		t.Errorf("Provenance = %v, want the stored provenance", stored.Provenance)
+	}
+
+	// The first contest is renamed under a fresh ID, the second one is no longer listed
+	renamed := newStoredKontest("Codeforces Round 1 (Div. 2)", "https://codeforces.com/contest/1", start, time.Now())
+	c := newStoredKontest("Codeforces Round 3", "https://codeforces.com/contest/3", start.Add(48*time.Hour), time.Now())
+	if err := repo.ReplaceAll(ctx, []model.KontestModel{renamed, c}); err != nil {
+		t.Fatalf("second ReplaceAll failed: %v", err)
+	}
+
+	kontests, err := repo.FindAll(ctx)
+	if err != nil {
+		t.Fatalf("FindAll failed: %v", err)
+	}
+	if len(kontests) != 2 {
+		t.Fatalf("FindAll returned %d contests, want 2: %+v", len(kontests), kontests)
+	}
+
+	stored, err = repo.FindByID(ctx, a.ID)
+	if err != nil {
+		t.Fatalf("renamed contest lost its ID: %v", err)
+	}
+	if stored.Name != "Codeforces Round 1 (Div. 2)" || !stored.FirstSeenAt.Equal(firstSeenAt) {
+		t.Errorf("renamed contest = %q first seen at %v, want the new name first seen at %v", stored.Name, stored.FirstSeenAt, firstSeenAt)
+	}
+	if _, err := repo.FindByID(ctx, c.ID); err != nil {
+		t.Errorf("new contest was not stored: %v", err)
+	}
+	if _, err := repo.FindByID(ctx, b.ID); !errors.Is(err, repository.ErrNotFound) {
+		t.Errorf("FindByID of the pruned contest = %v, want ErrNotFound", err)
+	}
+
+	if err := repo.ReplaceAll(ctx, nil); err != nil {
+		t.Fatalf("ReplaceAll with no contests failed: %v", err)
+	}
+	if kontests, err := repo.FindAll(ctx); err != nil || len(kontests) != 0 {
+		t.Errorf("FindAll after clearing returned %d contests (%v), want none", len(kontests), err)
+	}
+}
@@ -1,31 +1,43 @@
 package impl
 
 import (
-	"kontest-api/database"
+	"context"
+	"gorm.io/gorm"
 	"kontest-api/model"
 	"log"
 	"time"
 )
 
 // MetadataRepositoryImpl is a concrete implementation of the MetadataRepository interface.
-type MetadataRepositoryImpl struct{}
+type MetadataRepositoryImpl struct {
+	db           *gorm.DB
+	queryTimeout time.Duration
+}
 
-// NewMetadataRepository creates a new instance of MetadataRepositoryImpl.
-func NewMetadataRepository() *MetadataRepositoryImpl {
-	return &MetadataRepositoryImpl{}
+// NewMetadataRepository creates a new instance of MetadataRepositoryImpl using the given database.
+// Every operation is cancelled after queryTimeout.
+func NewMetadataRepository(db *gorm.DB, queryTimeout time.Duration) *MetadataRepositoryImpl {
+	return &MetadataRepositoryImpl{db: db, queryTimeout: queryTimeout}
 }
 
 // Save saves the metadata to the database.
-func (repo *MetadataRepositoryImpl) Save(metadata *model.Metadata) {
-	if err := database.GetDB().Save(metadata).Error; err != nil {
-		log.Fatalf("Error saving metadata: %v", err)
+func (repo *MetadataRepositoryImpl) Save(ctx context.Context, metadata *model.Metadata) error {
+	db, cancel := withTimeout(ctx, repo.db, repo.queryTimeout)
+	defer cancel()
+
+	if err := db.Save(metadata).Error; err != nil {
+		return newRepositoryError("save metadata", err)
 	}
+	return nil
 }
 
 // GetLastUpdatedAt fetches the last updated timestamp from the database.
-func (repo *MetadataRepositoryImpl) GetLastUpdatedAt() time.Time {
+func (repo *MetadataRepositoryImpl) GetLastUpdatedAt(ctx context.Context) time.Time {
+	db, cancel := withTimeout(ctx, repo.db, repo.queryTimeout)
+	defer cancel()
+
 	var metadata model.Metadata
-	if err := database.GetDB().Order("last_updated_at desc").First(&metadata).Error; err != nil {
+	if err := db.Order("last_updated_at desc").First(&metadata).Error; err != nil {
 		log.Printf("Error fetching last updated time: %v", err)
 		return time.Time{} // Return zero time if error occurs
 	}
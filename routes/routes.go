@@ -22,11 +22,13 @@ func HelloDELETEHandler(w http.ResponseWriter, r *http.Request) {
 	fmt.Fprintf(w, "Hello, World! DELETE")
 }
 
-func RegisterRoutes(router *http.ServeMux) {
-	router.HandleFunc("GET /kontests", controllers.GetAllKontests)
-	router.HandleFunc("GET /health", controllers.HealthCheck)
-	router.HandleFunc("GET /get_supported_sites", controllers.GetSupportedSites)
-	router.HandleFunc("DELETE /purge", controllers.PurgeMetadata)
+func RegisterRoutes(router *http.ServeMux, kontestHandler *controllers.KontestHandler) {
+	router.HandleFunc("GET /kontests", kontestHandler.GetAllKontests)
+	router.HandleFunc("GET /kontests/{id}", kontestHandler.GetKontest)
+	router.HandleFunc("GET /health", kontestHandler.HealthCheck)
+	router.HandleFunc("GET /status", kontestHandler.GetStatus)
+	router.HandleFunc("GET /get_supported_sites", kontestHandler.GetSupportedSites)
+	router.HandleFunc("DELETE /purge", kontestHandler.PurgeMetadata)
 
 	registerHelloRoutes(router)
 }
@@ -0,0 +1,231 @@
+package service
+
+import (
+	"kontest-api/model"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// KontestMerger merges contests reported by several sources into a single record per contest.
+type KontestMerger struct {
+	priority       map[string]int
+	startTolerance time.Duration
+}
+
+// NewKontestMerger creates a new KontestMerger. Sources earlier in the priority list win
+// when several sources provide the same field; unlisted sources rank after all listed ones.
+// Contests are only considered the same if their start times differ by at most startTolerance.
+func NewKontestMerger(priority []string, startTolerance time.Duration) *KontestMerger {
+	ranks := make(map[string]int, len(priority))
+	for i, source := range priority {
+		ranks[source] = i
+	}
+
+	return &KontestMerger{
+		priority:       ranks,
+		startTolerance: startTolerance,
+	}
+}
+
+// kontestGroup holds the contests that were matched as the same contest, highest priority first.
+type kontestGroup struct {
+	members   []model.KontestModel
+	startTime time.Time
+	nameKey   nameKey
+	sources   map[string]bool
+}
+
+// Merge de-duplicates the given contests and returns one merged contest per group of matches.
+func (m *KontestMerger) Merge(kontests []model.KontestModel) []model.KontestModel {
+	// Process the highest priority sources first so they become the base of each group
+	ordered := make([]model.KontestModel, len(kontests))
+	copy(ordered, kontests)
+	sort.SliceStable(ordered, func(i, j int) bool {
+		return m.rank(ordered[i].Source) < m.rank(ordered[j].Source)
+	})
+
+	var groups []*kontestGroup
+	groupsBySite := make(map[string][]*kontestGroup)
+
+	for _, kontest := range ordered {
+		key := newNameKey(kontest.Name)
+
+		var match *kontestGroup
+		for _, group := range groupsBySite[kontest.SiteAbbreviation] {
+			if m.matches(group, kontest, key) {
+				match = group
+				break
+			}
+		}
+
+		if match == nil {
+			match = &kontestGroup{
+				startTime: kontest.StartTime,
+				nameKey:   key,
+				sources:   make(map[string]bool),
+			}
+			groups = append(groups, match)
+			groupsBySite[kontest.SiteAbbreviation] = append(groupsBySite[kontest.SiteAbbreviation], match)
+		}
+
+		match.members = append(match.members, kontest)
+		match.sources[kontest.Source] = true
+	}
+
+	merged := make([]model.KontestModel, 0, len(groups))
+	for _, group := range groups {
+		merged = append(merged, mergeGroup(group.members))
+	}
+	return merged
+}
+
+func (m *KontestMerger) rank(source string) int {
+	if rank, ok := m.priority[source]; ok {
+		return rank
+	}
+	return len(m.priority)
+}
+
+// matches reports whether the contest describes the same contest as the group.
+func (m *KontestMerger) matches(group *kontestGroup, kontest model.KontestModel, key nameKey) bool {
+	// A source never reports the same contest twice, so its contests are kept apart
+	if group.sources[kontest.Source] {
+		return false
+	}
+
+	diff := kontest.StartTime.Sub(group.startTime)
+	if diff < 0 {
+		diff = -diff
+	}
+	if diff > m.startTolerance {
+		return false
+	}
+
+	if kontest.URL != "" && model.NormalizeURL(kontest.URL) == model.NormalizeURL(group.members[0].URL) {
+		return true
+	}
+	return group.nameKey.similar(key)
+}
+
+// mergeGroup combines the members of a group, taking every field from the highest priority
+// member that provides it and recording which source each field came from. The merged contest
+// keeps the natural keys of all members, so it can be matched whichever source reported it before.
+func mergeGroup(members []model.KontestModel) model.KontestModel {
+	merged := members[0]
+	merged.Provenance = make(map[string]string)
+
+	merged.IdentityKeys = nil
+	for i := range members {
+		key := members[i].NaturalKey
+		if key == "" {
+			key = members[i].ComputeNaturalKey()
+		}
+		if !slices.Contains(merged.IdentityKeys, key) {
+			merged.IdentityKeys = append(merged.IdentityKeys, key)
+		}
+	}
+
+	stringFields := []struct {
+		name  string
+		value func(k *model.KontestModel) *string
+	}{
+		{"name", func(k *model.KontestModel) *string { return &k.Name }},
+		{"url", func(k *model.KontestModel) *string { return &k.URL }},
+		{"location", func(k *model.KontestModel) *string { return &k.Location }},
+		{"source_resource_id", func(k *model.KontestModel) *string { return &k.SourceResourceID }},
+	}
+
+	for _, field := range stringFields {
+		for i := range members {
+			if value := *field.value(&members[i]); value != "" {
+				*field.value(&merged) = value
+				merged.Provenance[field.name] = members[i].Source
+				break
+			}
+		}
+	}
+
+	timeFields := []struct {
+		name  string
+		value func(k *model.KontestModel) *time.Time
+	}{
+		{"start_time", func(k *model.KontestModel) *time.Time { return &k.StartTime }},
+		{"end_time", func(k *model.KontestModel) *time.Time { return &k.EndTime }},
+	}
+
+	for _, field := range timeFields {
+		for i := range members {
+			if value := *field.value(&members[i]); !value.IsZero() {
+				*field.value(&merged) = value
+				merged.Provenance[field.name] = members[i].Source
+				break
+			}
+		}
+	}
+
+	return merged
+}
+
+// nameKey is the normalized form of a contest name used for fuzzy matching.
+type nameKey struct {
+	words   map[string]bool
+	numbers map[string]bool
+}
+
+func newNameKey(name string) nameKey {
+	key := nameKey{
+		words:   make(map[string]bool),
+		numbers: make(map[string]bool),
+	}
+
+	tokens := strings.FieldsFunc(strings.ToLower(name), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+	for _, token := range tokens {
+		if strings.IndexFunc(token, unicode.IsLetter) == -1 {
+			// Strip leading zeros so "Round 09" and "Round 9" compare equal
+			if trimmed := strings.TrimLeft(token, "0"); trimmed != "" {
+				token = trimmed
+			}
+			key.numbers[token] = true
+		} else {
+			key.words[token] = true
+		}
+	}
+	return key
+}
+
+// similar reports whether two names refer to the same contest. The numbers in both names
+// must be identical, so that e.g. "Div. 1" and "Div. 2" rounds are never merged, and the
+// words must either overlap substantially or one set must contain the other.
+func (k nameKey) similar(other nameKey) bool {
+	if len(k.numbers) != len(other.numbers) {
+		return false
+	}
+	for number := range k.numbers {
+		if !other.numbers[number] {
+			return false
+		}
+	}
+
+	common := 0
+	for word := range k.words {
+		if other.words[word] {
+			common++
+		}
+	}
+
+	smaller := min(len(k.words), len(other.words))
+	if smaller == 0 {
+		return len(k.words) == len(other.words)
+	}
+	if common == smaller {
+		return true
+	}
+
+	union := len(k.words) + len(other.words) - common
+	return float64(common)/float64(union) >= 0.6
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"kontest-api/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+// newSourceKontest returns a two hour contest on codeforces.com as reported by the given source
+func newSourceKontest(source, name, url string, start time.Time) model.KontestModel {
+	kontest := model.NewKontestModel(name, url, start, start.Add(2*time.Hour), "codeforces.com")
+	kontest.Source = source
+	return *kontest
+}
+
+func TestKontestMergerMerge(t *testing.T) {
+	start := time.Date(2024, 7, 11, 14, 35, 0, 0, time.UTC)
+
+	tests := []struct {
+		name     string
+		kontests []model.KontestModel
+		want     []string // Names of the merged contests
+	}{
+		{
+			name: "same URL",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Codeforces Round 956 (Div. 2)", "https://codeforces.com/contest/1983", start),
+				newSourceKontest("clist-api", "Codeforces Round #956 Div 2 and ByteRace", "http://www.codeforces.com/contest/1983/", start),
+			},
+			want: []string{"Codeforces Round 956 (Div. 2)"},
+		},
+		{
+			name: "divisions at the same time",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Codeforces Round 956 (Div. 1)", "https://codeforces.com/contest/1982", start),
+				newSourceKontest("clist-api", "Codeforces Round 956 (Div. 2)", "https://codeforces.com/contests/1983", start),
+			},
+			want: []string{"Codeforces Round 956 (Div. 1)", "Codeforces Round 956 (Div. 2)"},
+		},
+		{
+			name: "numbers with leading zeros",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Educational Round 09", "https://codeforces.com/contest/632", start),
+				newSourceKontest("clist-api", "Educational Round 9", "https://codeforces.com/contests/632", start),
+			},
+			want: []string{"Educational Round 09"},
+		},
+		{
+			name: "different numbers",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Codeforces Round 956", "https://codeforces.com/contest/1983", start),
+				newSourceKontest("clist-api", "Codeforces Round 957", "https://codeforces.com/contests/1984", start),
+			},
+			want: []string{"Codeforces Round 956", "Codeforces Round 957"},
+		},
+		{
+			name: "word overlap at the threshold",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Codeforces Global Round 956 Sponsored", "https://codeforces.com/contest/1983", start),
+				newSourceKontest("clist-api", "Codeforces Global Round 956 Summer", "https://codeforces.com/contests/1983", start),
+			},
+			want: []string{"Codeforces Global Round 956 Sponsored"},
+		},
+		{
+			name: "word overlap below the threshold",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Codeforces Beginner Round 956", "https://codeforces.com/contest/1983", start),
+				newSourceKontest("clist-api", "Codeforces Regular Round 956", "https://codeforces.com/contests/1983", start),
+			},
+			want: []string{"Codeforces Beginner Round 956", "Codeforces Regular Round 956"},
+		},
+		{
+			name: "words contained in the other name",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Codeforces Round 956", "https://codeforces.com/contest/1983", start),
+				newSourceKontest("clist-api", "Codeforces Round 956 and ByteRace", "https://codeforces.com/contests/1983", start),
+			},
+			want: []string{"Codeforces Round 956"},
+		},
+		{
+			name: "numbers missing from the other name",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Codeforces Round 956", "https://codeforces.com/contest/1983", start),
+				newSourceKontest("clist-api", "Codeforces Round 956 (Div. 2) and ByteRace 2024", "https://codeforces.com/contests/1983", start),
+			},
+			want: []string{"Codeforces Round 956", "Codeforces Round 956 (Div. 2) and ByteRace 2024"},
+		},
+		{
+			name: "start times at the tolerance",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Codeforces Round 956", "https://codeforces.com/contest/1983", start),
+				newSourceKontest("clist-api", "Codeforces Round 956", "https://codeforces.com/contests/1983", start.Add(15*time.Minute)),
+			},
+			want: []string{"Codeforces Round 956"},
+		},
+		{
+			name: "start times beyond the tolerance",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Codeforces Round 956", "https://codeforces.com/contest/1983", start),
+				newSourceKontest("clist-api", "Codeforces Round 956", "https://codeforces.com/contest/1983", start.Add(15*time.Minute+time.Second)),
+			},
+			want: []string{"Codeforces Round 956", "Codeforces Round 956"},
+		},
+		{
+			name: "same source",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Codeforces Round 956", "https://codeforces.com/contest/1983", start),
+				newSourceKontest("codeforces", "Codeforces Round 956", "https://codeforces.com/contest/1983", start),
+			},
+			want: []string{"Codeforces Round 956", "Codeforces Round 956"},
+		},
+		{
+			name: "different sites",
+			kontests: []model.KontestModel{
+				newSourceKontest("codeforces", "Weekly Contest 406", "", start),
+				*model.NewKontestModel("Weekly Contest 406", "", start, start.Add(time.Hour), "leetcode.com"),
+			},
+			want: []string{"Weekly Contest 406", "Weekly Contest 406"},
+		},
+	}
+
+	merger := NewKontestMerger([]string{"codeforces", "clist-api"}, 15*time.Minute)
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, kontest := range merger.Merge(tt.kontests) {
+				names = append(names, kontest.Name)
+			}
+			slices.Sort(names)
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("merged contests = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestKontestMergerPriority(t *testing.T) {
+	start := time.Date(2024, 7, 11, 14, 35, 0, 0, time.UTC)
+	clist := newSourceKontest("clist-api", "Codeforces Round #956 (Div. 2)", "https://codeforces.com/contests/1983", start.Add(5*time.Minute))
+	clist.SourceResourceID = "1"
+	clist.Location = "codeforces.com"
+	codeforces := newSourceKontest("codeforces", "Codeforces Round 956 (Div. 2)", "https://codeforces.com/contest/1983", start)
+	codeforces.Location = ""
+
+	// The lower priority source is listed first, and an unlisted source ranks last
+	unlisted := newSourceKontest("clist", "Codeforces Round 956 (Div. 2)", "https://codeforces.com/contest/1983", start)
+	merged := NewKontestMerger([]string{"codeforces", "clist-api"}, 15*time.Minute).
+		Merge([]model.KontestModel{unlisted, clist, codeforces})
+	if len(merged) != 1 {
+		t.Fatalf("got %d merged contests, want 1: %+v", len(merged), merged)
+	}
+	kontest := merged[0]
+
+	if kontest.Name != codeforces.Name || kontest.URL != codeforces.URL || !kontest.StartTime.Equal(start) {
+		t.Errorf("merged contest = %q %q %v, want the fields of the codeforces contest", kontest.Name, kontest.URL, kontest.StartTime)
+	}
+	if kontest.Source != "codeforces" {
+		t.Errorf("Source = %q, want codeforces", kontest.Source)
+	}
+
+	// Fields missing from the highest priority contest are filled from the next source that has them
+	if kontest.Location != "codeforces.com" || kontest.SourceResourceID != "1" {
+		t.Errorf("Location = %q, SourceResourceID = %q, want the values of the clist-api contest", kontest.Location, kontest.SourceResourceID)
+	}
+	wantProvenance := map[string]string{
+		"name":               "codeforces",
+		"url":                "codeforces",
+		"start_time":         "codeforces",
+		"end_time":           "codeforces",
+		"location":           "clist-api",
+		"source_resource_id": "clist-api",
+	}
+	for field, source := range wantProvenance {
+		if kontest.Provenance[field] != source {
+			t.Errorf("Provenance[%q] = %q, want %q", field, kontest.Provenance[field], source)
+		}
+	}
+	if len(kontest.Provenance) != len(wantProvenance) {
+		t.Errorf("Provenance = %v, want %v", kontest.Provenance, wantProvenance)
+	}
+}
@@ -0,0 +1,182 @@
+package service
+
+import (
+	"errors"
+	"kontest-api/model"
+	"kontest-api/utils/enums"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrSnapshotExpired is returned when a query asks for a snapshot that is no longer retained
+var ErrSnapshotExpired = errors.New("snapshot is no longer available")
+
+// KontestQuery selects and paginates contests. Zero-valued fields do not filter.
+type KontestQuery struct {
+	Version uint64    // Version of the snapshot to query, 0 queries the current snapshot
+	At      time.Time // Time the statuses are evaluated at, the zero time uses the current time
+
+	Sites    []string              // Site abbreviations the contests are hosted on
+	Statuses []enums.ContestStatus // Statuses of the contests at the time of the query
+
+	StartsAfter  time.Time // Contests starting at or after this time
+	StartsBefore time.Time // Contests starting before this time
+
+	MinDuration time.Duration // Contests lasting at least this long
+	MaxDuration time.Duration // Contests lasting at most this long
+
+	Text string // Case-insensitive substring of the contest name
+
+	// Sort orders the results by the keys in order of precedence, after which contests are
+	// ordered by start time, end time and site
+	Sort []SortKey
+
+	Offset int // Number of matching contests to skip
+	Limit  int // Maximum number of contests to return, 0 returns every matching contest
+}
+
+// KontestQueryResult is a page of the contests matching a query.
+type KontestQueryResult struct {
+	Contests []model.KontestModel
+	Total    int       // Number of contests matching the query across all pages
+	Version  uint64    // Version of the snapshot the query ran against
+	Now      time.Time // Time the statuses were evaluated at
+}
+
+// Query returns the contests matching the query at the given time, sorted as requested. The indexes
+// narrow the contests down to a range of start times, the contests of the requested sites or the
+// contests finished by now, so only those candidates are checked against the query.
+func (s *KontestSnapshot) Query(query KontestQuery, now time.Time) *KontestQueryResult {
+	// Names are searched in their lowercase form
+	query.Text = strings.ToLower(query.Text)
+
+	var matches []int
+	for _, position := range s.candidates(query, now) {
+		if s.matches(&query, position, now) {
+			matches = append(matches, position)
+		}
+	}
+	if len(query.Sort) > 0 {
+		s.sortPositions(matches, query.Sort)
+	}
+
+	result := &KontestQueryResult{Total: len(matches), Version: s.Version, Now: now}
+
+	start := min(max(query.Offset, 0), len(matches))
+	end := len(matches)
+	if query.Limit > 0 {
+		end = min(start+query.Limit, end)
+	}
+
+	result.Contests = make([]model.KontestModel, 0, end-start)
+	for _, position := range matches[start:end] {
+		result.Contests = append(result.Contests, s.contests[position])
+	}
+	return result
+}
+
+// candidates returns the positions of the contests that may match the query, in contest order.
+// Every contest that matches the query is among them.
+func (s *KontestSnapshot) candidates(query KontestQuery, now time.Time) []int {
+	lo, hi := s.startRange(query, now)
+	if lo >= hi {
+		return nil
+	}
+
+	if len(query.Sites) > 0 {
+		var positions []int
+		for _, site := range query.Sites {
+			sitePositions := s.bySite[site]
+			first := sort.SearchInts(sitePositions, lo)
+			last := sort.SearchInts(sitePositions, hi)
+			positions = append(positions, sitePositions[first:last]...)
+		}
+
+		// Positions of different sites are interleaved, sorting them restores the contest order
+		sort.Ints(positions)
+		return slices.Compact(positions)
+	}
+
+	if slices.Equal(query.Statuses, []enums.ContestStatus{enums.StatusFinished}) {
+		finished := sort.Search(len(s.byEnd), func(i int) bool {
+			return s.contests[s.byEnd[i]].EndTime.After(now)
+		})
+		if finished < hi-lo {
+			positions := slices.Clone(s.byEnd[:finished])
+			sort.Ints(positions)
+			return positions
+		}
+	}
+
+	positions := make([]int, hi-lo)
+	for i := range positions {
+		positions[i] = lo + i
+	}
+	return positions
+}
+
+// startRange returns the range of positions whose start times can match the query. Contests are
+// ordered by start time, so both the time window and the upcoming status map to a range.
+func (s *KontestSnapshot) startRange(query KontestQuery, now time.Time) (int, int) {
+	lo, hi := 0, len(s.contests)
+	if !query.StartsAfter.IsZero() {
+		lo = s.firstStartingAtOrAfter(query.StartsAfter)
+	}
+	if !query.StartsBefore.IsZero() {
+		hi = min(hi, s.firstStartingAtOrAfter(query.StartsBefore))
+	}
+
+	if len(query.Statuses) > 0 {
+		// Upcoming contests start after now, running and finished ones at or before it
+		started := sort.Search(len(s.contests), func(i int) bool {
+			return s.contests[i].StartTime.After(now)
+		})
+		if !slices.Contains(query.Statuses, enums.StatusUpcoming) {
+			hi = min(hi, started)
+		}
+		if !slices.Contains(query.Statuses, enums.StatusRunning) && !slices.Contains(query.Statuses, enums.StatusFinished) {
+			lo = max(lo, started)
+		}
+	}
+	return lo, hi
+}
+
+// firstStartingAtOrAfter returns the position of the first contest starting at or after t
+func (s *KontestSnapshot) firstStartingAtOrAfter(t time.Time) int {
+	return sort.Search(len(s.contests), func(i int) bool {
+		return !s.contests[i].StartTime.Before(t)
+	})
+}
+
+// matches reports whether the contest at the position matches every filter of the query at the
+// given time. The query's text must be in lowercase.
+func (s *KontestSnapshot) matches(q *KontestQuery, position int, now time.Time) bool {
+	kontest := &s.contests[position]
+	if len(q.Sites) > 0 && !slices.Contains(q.Sites, kontest.SiteAbbreviation) {
+		return false
+	}
+	if len(q.Statuses) > 0 && !slices.Contains(q.Statuses, kontest.StatusAt(now)) {
+		return false
+	}
+	if !q.StartsAfter.IsZero() && kontest.StartTime.Before(q.StartsAfter) {
+		return false
+	}
+	if !q.StartsBefore.IsZero() && !kontest.StartTime.Before(q.StartsBefore) {
+		return false
+	}
+
+	duration := kontest.EndTime.Sub(kontest.StartTime)
+	if q.MinDuration > 0 && duration < q.MinDuration {
+		return false
+	}
+	if q.MaxDuration > 0 && duration > q.MaxDuration {
+		return false
+	}
+
+	if q.Text != "" && !strings.Contains(s.lowerNames[position], q.Text) {
+		return false
+	}
+	return true
+}
@@ -0,0 +1,188 @@
+package service
+
+import (
+	"fmt"
+	"kontest-api/model"
+	"kontest-api/utils/enums"
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+var (
+	testSites    = []string{"CodeForces", "AtCoder", "LeetCode", "CodeChef"}
+	testSiteURLs = map[string]string{"CodeForces": "codeforces.com", "AtCoder": "atcoder.jp", "LeetCode": "leetcode.com", "CodeChef": "codechef.com"}
+	testWords    = []string{"Round", "Beginner", "Weekly", "Starters", "Div. 2", "Heuristic"}
+)
+
+// randomSnapshot returns a snapshot of contests on a grid of hours around now, so that contests
+// often start, end or run exactly at the boundaries used by the queries
+func randomSnapshot(rng *rand.Rand, now time.Time) *KontestSnapshot {
+	kontests := make([]model.KontestModel, rng.IntN(40))
+	for i := range kontests {
+		site := testSites[rng.IntN(len(testSites))]
+		start := now.Add(time.Duration(rng.IntN(21)-10) * time.Hour)
+		duration := time.Duration(rng.IntN(6)) * time.Hour
+		name := fmt.Sprintf("%s %s %d", site, testWords[rng.IntN(len(testWords))], rng.IntN(5))
+		kontests[i] = *model.NewKontestModel(name, "", start, start.Add(duration), testSiteURLs[site])
+	}
+	return newKontestSnapshot(1, now, kontests)
+}
+
+// randomQuery returns a query using a random combination of the filters
+func randomQuery(rng *rand.Rand, now time.Time) KontestQuery {
+	var query KontestQuery
+	randomTime := func() time.Time {
+		return now.Add(time.Duration(rng.IntN(25)-12) * time.Hour)
+	}
+
+	for _, site := range append(slices.Clone(testSites), "HackerRank") {
+		if rng.IntN(4) == 0 {
+			query.Sites = append(query.Sites, site)
+			if rng.IntN(4) == 0 {
+				query.Sites = append(query.Sites, site) // Sites may be repeated
+			}
+		}
+	}
+	for _, status := range enums.GetAllStatuses() {
+		if rng.IntN(3) == 0 {
+			query.Statuses = append(query.Statuses, status)
+		}
+	}
+	if rng.IntN(3) == 0 {
+		query.StartsAfter = randomTime()
+	}
+	if rng.IntN(3) == 0 {
+		query.StartsBefore = randomTime()
+	}
+	if rng.IntN(4) == 0 {
+		query.MinDuration = time.Duration(rng.IntN(4)) * time.Hour
+	}
+	if rng.IntN(4) == 0 {
+		query.MaxDuration = time.Duration(rng.IntN(5)) * time.Hour
+	}
+	if rng.IntN(4) == 0 {
+		query.Text = strings.ToUpper(testWords[rng.IntN(len(testWords))])
+	}
+	if rng.IntN(3) == 0 {
+		query.Offset = rng.IntN(10)
+		query.Limit = rng.IntN(10)
+	}
+	return query
+}
+
+// linearQuery filters the contests of the snapshot one by one, without using its indexes
+func linearQuery(s *KontestSnapshot, query KontestQuery, now time.Time) []model.KontestModel {
+	var matches []model.KontestModel
+	for _, kontest := range s.contests {
+		switch {
+		case len(query.Sites) > 0 && !slices.Contains(query.Sites, kontest.SiteAbbreviation),
+			len(query.Statuses) > 0 && !slices.Contains(query.Statuses, kontest.StatusAt(now)),
+			!query.StartsAfter.IsZero() && kontest.StartTime.Before(query.StartsAfter),
+			!query.StartsBefore.IsZero() && !kontest.StartTime.Before(query.StartsBefore),
+			query.MinDuration > 0 && kontest.EndTime.Sub(kontest.StartTime) < query.MinDuration,
+			query.MaxDuration > 0 && kontest.EndTime.Sub(kontest.StartTime) > query.MaxDuration,
+			query.Text != "" && !strings.Contains(strings.ToLower(kontest.Name), strings.ToLower(query.Text)):
+			continue
+		}
+		matches = append(matches, kontest)
+	}
+
+	start := min(query.Offset, len(matches))
+	end := len(matches)
+	if query.Limit > 0 {
+		end = min(start+query.Limit, end)
+	}
+	return matches[start:end]
+}
+
+func TestKontestSnapshotQueryMatchesLinearFilter(t *testing.T) {
+	rng := rand.New(rand.NewPCG(1, 2))
+	now := time.Date(2024, 7, 6, 12, 0, 0, 0, time.UTC)
+
+	for i := 0; i < 2000; i++ {
+		snapshot := randomSnapshot(rng, now)
+		query := randomQuery(rng, now)
+
+		got := snapshot.Query(query, now).Contests
+		want := linearQuery(snapshot, query, now)
+
+		ids := func(kontests []model.KontestModel) []string {
+			result := make([]string, len(kontests))
+			for j, kontest := range kontests {
+				result[j] = kontest.ID.String()
+			}
+			return result
+		}
+		if !slices.Equal(ids(got), ids(want)) {
+			t.Fatalf("snapshot %d: Query(%+v) returned %d contests, want %d:\ngot  %v\nwant %v",
+				i, query, len(got), len(want), ids(got), ids(want))
+		}
+	}
+}
+
+func TestKontestSnapshotQueryIndexes(t *testing.T) {
+	now := time.Date(2024, 7, 6, 12, 0, 0, 0, time.UTC)
+	kontest := func(name, location string, start, end time.Duration) model.KontestModel {
+		return *model.NewKontestModel(name, "", now.Add(start), now.Add(end), location)
+	}
+	// A long running contest started before contests that have already finished
+	snapshot := newKontestSnapshot(1, now, []model.KontestModel{
+		kontest("Heuristic", "atcoder.jp", -48*time.Hour, 48*time.Hour),
+		kontest("Finished", "codeforces.com", -5*time.Hour, -3*time.Hour),
+		kontest("Ended now", "atcoder.jp", -2*time.Hour, 0),
+		kontest("Running", "codeforces.com", -time.Hour, time.Hour),
+		kontest("Starts now", "leetcode.com", 0, 2*time.Hour),
+		kontest("Upcoming", "codeforces.com", time.Hour, 3*time.Hour),
+	})
+
+	tests := []struct {
+		name  string
+		query KontestQuery
+		want  []string
+	}{
+		{
+			name:  "finished only",
+			query: KontestQuery{Statuses: []enums.ContestStatus{enums.StatusFinished}},
+			want:  []string{"Finished", "Ended now"},
+		},
+		{
+			name:  "upcoming only",
+			query: KontestQuery{Statuses: []enums.ContestStatus{enums.StatusUpcoming}},
+			want:  []string{"Upcoming"},
+		},
+		{
+			name:  "running and finished",
+			query: KontestQuery{Statuses: []enums.ContestStatus{enums.StatusRunning, enums.StatusFinished}},
+			want:  []string{"Heuristic", "Finished", "Ended now", "Running", "Starts now"},
+		},
+		{
+			name:  "sites in contest order",
+			query: KontestQuery{Sites: []string{"LeetCode", "AtCoder", "LeetCode"}},
+			want:  []string{"Heuristic", "Ended now", "Starts now"},
+		},
+		{
+			name:  "site within a start range",
+			query: KontestQuery{Sites: []string{"CodeForces"}, StartsAfter: now.Add(-time.Hour), StartsBefore: now.Add(time.Hour)},
+			want:  []string{"Running"},
+		},
+		{
+			name:  "empty start range",
+			query: KontestQuery{StartsAfter: now, StartsBefore: now},
+			want:  nil,
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, kontest := range snapshot.Query(tt.query, now).Contests {
+				names = append(names, kontest.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("Query returned %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
@@ -2,306 +2,375 @@
 package service
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
-	"github.com/PuerkitoBio/goquery"
-	"io"
-	"kontest-api/database"
+	"github.com/google/uuid"
 	"kontest-api/model"
 	"kontest-api/repository"
+	"kontest-api/sources"
 	"log"
-	"net/http"
-	"sort"
-	"strings"
+	"slices"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
-const updateInterval = time.Hour
-
 type KontestService struct {
-	kontestRepo   repository.KontestRepository
-	metadataRepo  repository.MetadataRepository
-	url           string
-	lastUpdatedAt time.Time
-	updateMutex   sync.Mutex
-	kontestsCache []model.KontestModel // Cache variable
-	isUpdating    sync.Mutex
-}
+	kontestRepo  repository.KontestRepository
+	metadataRepo repository.MetadataRepository
+	sources      *sources.Registry
+	merger       *KontestMerger
 
-func NewKontestService(kontestRepository repository.KontestRepository, metadataRepository repository.MetadataRepository) *KontestService {
-	var kontests []model.KontestModel
+	// history holds the snapshot of the contests being served, and the recently superseded ones.
+	// Readers load it without locking, and a refresh replaces it as a whole.
+	history atomic.Pointer[snapshotHistory]
 
-	// Fetch contests from the database
-	database.GetDB().Find(&kontests)
+	// snapshotRetention is how long a superseded snapshot can still be queried
+	snapshotRetention time.Duration
 
-	return &KontestService{
-		kontestRepo:   kontestRepository,
-		metadataRepo:  metadataRepository,
-		url:           "https://clist.by",
-		lastUpdatedAt: metadataRepository.GetLastUpdatedAt(),
-		kontestsCache: kontests, // Initialize the cache with fetched contests
-	}
-}
+	// updateMutex serializes refreshes, it guards sourceResults
+	updateMutex sync.Mutex
 
-func (s *KontestService) fetchHtml() {
-	s.updateMutex.Lock()
-	defer s.updateMutex.Unlock()
+	// sourceResults holds the latest contests fetched from each source, keyed by source name
+	sourceResults map[string][]model.KontestModel
 
-	// Check if an update is needed
-	if time.Since(s.lastUpdatedAt) < updateInterval {
-		log.Println("Update is not required.")
-		return
-	}
+	// statusMutex guards status separately so it can be read while a refresh is running
+	statusMutex sync.RWMutex
+	status      RefreshStatus
+}
 
-	// Fetch HTML content from the URL
-	resp, err := http.Get(s.url)
+// NewKontestService creates a new KontestService serving the stored contests. Snapshots replaced
+// by a refresh can still be queried by version until snapshotRetention has passed.
+func NewKontestService(ctx context.Context, kontestRepository repository.KontestRepository, metadataRepository repository.MetadataRepository, registry *sources.Registry, merger *KontestMerger, snapshotRetention time.Duration) *KontestService {
+	// Fetch contests from the database, starting with an empty cache if they cannot be loaded
+	kontests, err := kontestRepository.FindAll(ctx)
 	if err != nil {
-		log.Fatalf("Failed to fetch HTML content: %v", err)
-		return
+		log.Printf("Starting without stored contests: %v", err)
 	}
-	defer func(Body io.ReadCloser) {
-		err := Body.Close()
-		if err != nil {
-			log.Fatalf("Failed to close response body: %v", err)
-		}
-	}(resp.Body)
-
-	if resp.StatusCode != http.StatusOK {
-		log.Fatalf("Failed to fetch HTML content: received status %s", resp.Status)
-		return
+	lastUpdatedAt := metadataRepository.GetLastUpdatedAt(ctx)
+
+	s := &KontestService{
+		kontestRepo:       kontestRepository,
+		metadataRepo:      metadataRepository,
+		sources:           registry,
+		merger:            merger,
+		snapshotRetention: snapshotRetention,
+		sourceResults:     groupBySource(kontests),
+		status:            RefreshStatus{LastUpdatedAt: lastUpdatedAt},
 	}
+	// Initialize the snapshot with the stored contests. Versions start at the start time of the
+	// service, so that cursors issued before a restart cannot match a snapshot of this process.
+	initialVersion := uint64(time.Now().UnixNano())
+	s.history.Store(s.history.Load().publish(newKontestSnapshot(initialVersion, lastUpdatedAt, kontests), snapshotRetention))
+	return s
+}
 
-	// Read the response body
-	body, err := io.ReadAll(resp.Body) // Read all the response body
-	if err != nil {
-		log.Fatalf("Failed to read response body: %v", err)
-		return
+// groupBySource seeds the per-source results from previously stored contests, so that
+// refreshing a single source does not drop the contests of the others.
+func groupBySource(kontests []model.KontestModel) map[string][]model.KontestModel {
+	results := make(map[string][]model.KontestModel)
+	for _, kontest := range kontests {
+		results[kontest.Source] = append(results[kontest.Source], kontest)
 	}
+	return results
+}
 
-	log.Println("Fetched HTML content successfully.")
-
-	kontests := s.parseContests(string(body))
-
-	const timeLayout = "January 2, 2006 15:04:05"
+// LastUpdatedAt returns the time the contests were last refreshed
+func (s *KontestService) LastUpdatedAt() time.Time {
+	return s.history.Load().current.UpdatedAt
+}
 
-	// Sort the kontests slice
-	sort.Slice(kontests, func(i, j int) bool {
-		// Parse StartTime
-		startTimeI, errI := time.Parse(timeLayout, kontests[i].StartTime)
-		startTimeJ, errJ := time.Parse(timeLayout, kontests[j].StartTime)
+// Snapshot returns the snapshot of the contests currently served. It never blocks.
+func (s *KontestService) Snapshot() *KontestSnapshot {
+	return s.history.Load().current
+}
 
-		// If parsing fails, you might want to handle the error or set a default time
-		if errI != nil || errJ != nil {
-			// Handle error, e.g., log it or ignore the entry
-			return false // or any logic you want to apply when there's an error
+// Status returns the current refresh status, including the outcome of each enabled source.
+func (s *KontestService) Status() RefreshStatus {
+	s.statusMutex.RLock()
+	defer s.statusMutex.RUnlock()
+
+	status := s.status
+	status.Sources = make([]SourceStatus, 0, len(s.status.Sources))
+	for _, source := range s.sources.Enabled() {
+		if sourceStatus := s.findSourceStatus(source.Name()); sourceStatus != nil {
+			status.Sources = append(status.Sources, *sourceStatus)
+		} else {
+			status.Sources = append(status.Sources, SourceStatus{Name: source.Name()})
 		}
+	}
+	return status
+}
 
-		// Sort by StartTime
-		if !startTimeI.Equal(startTimeJ) {
-			return startTimeI.Before(startTimeJ)
+// findSourceStatus returns the status entry of the named source. The caller must hold statusMutex.
+func (s *KontestService) findSourceStatus(name string) *SourceStatus {
+	for i := range s.status.Sources {
+		if s.status.Sources[i].Name == name {
+			return &s.status.Sources[i]
 		}
+	}
+	return nil
+}
 
-		// Parse EndTime
-		endTimeI, errI := time.Parse(timeLayout, kontests[i].EndTime)
-		endTimeJ, errJ := time.Parse(timeLayout, kontests[j].EndTime)
+// recordSourceResult updates the status of a source after a fetch attempt.
+func (s *KontestService) recordSourceResult(name string, attemptedAt time.Time, err error) {
+	s.statusMutex.Lock()
+	defer s.statusMutex.Unlock()
 
-		// Handle error similarly
-		if errI != nil || errJ != nil {
-			return false // or any logic you want to apply when there's an error
-		}
-
-		// Sort by EndTime
-		if !endTimeI.Equal(endTimeJ) {
-			return endTimeI.Before(endTimeJ)
-		}
+	status := s.findSourceStatus(name)
+	if status == nil {
+		s.status.Sources = append(s.status.Sources, SourceStatus{Name: name})
+		status = &s.status.Sources[len(s.status.Sources)-1]
+	}
 
-		// Finally sort by SiteAbbreviation
-		return kontests[i].SiteAbbreviation < kontests[j].SiteAbbreviation
-	})
+	status.LastAttemptAt = attemptedAt
+	if err != nil {
+		status.LastError = err.Error()
+		status.ConsecutiveFailures++
+		return
+	}
+	status.LastSuccessAt = attemptedAt
+	status.LastError = ""
+	status.ConsecutiveFailures = 0
+}
 
-	// Clear existing contests and save new ones
-	s.kontestRepo.DeleteAll()
-	s.saveKontestsToDB(kontests)
+// RefreshSources fetches the named sources and rebuilds the contest cache from the latest
+// results of every enabled source. Sources that are not enabled are ignored.
+//
+// If the context is cancelled while fetching, the refresh is abandoned as a whole: nothing
+// fetched is kept and the context's error is returned. A cancellation while persisting rolls
+// back the replacement of the stored contests.
+//
+// Failures do not discard the cache: sources that fail keep their previous contests and a
+// failure to persist the contests still updates the cache. The returned error joins a
+// *SourceError for every source that failed and a *repository.RepositoryError if the
+// contests could not be persisted.
+func (s *KontestService) RefreshSources(ctx context.Context, names []string) error {
+	s.updateMutex.Lock()
+	defer s.updateMutex.Unlock()
 
-	// Update cache
-	s.kontestsCache = kontests
+	enabled := s.sources.Enabled()
 
-	// Update metadata
-	s.metadataRepo.Save(model.NewMetadata())
-	s.lastUpdatedAt = time.Now()
-}
+	var selected []sources.ContestSource
+	for _, source := range enabled {
+		if slices.Contains(names, source.Name()) {
+			selected = append(selected, source)
+		}
+	}
 
-func (s *KontestService) parseContests(html string) []model.KontestModel {
-	var kontestModels []model.KontestModel
+	if len(selected) == 0 {
+		return nil
+	}
 
-	// Parse HTML using goquery
-	doc, err := goquery.NewDocumentFromReader(strings.NewReader(html))
+	sourceErrs, err := s.fetchFromSources(ctx, selected)
 	if err != nil {
-		log.Fatalf("Failed to parse HTML: %v", err)
+		log.Printf("Refresh cancelled, keeping the existing contests: %v", err)
+		return err
+	}
+	if len(sourceErrs) == len(selected) {
+		log.Println("No contest source could be fetched, keeping the existing contests.")
+		return errors.Join(sourceErrs...)
 	}
 
-	k := doc.Find("tr.contest")
-	fmt.Println("Total contest rows found:", k.Length())
-
-	k.Each(func(i int, s *goquery.Selection) {
-		// Extracting specific fields for each contest
-		startTime := s.Find("td.start-time a").Text()
-		duration := s.Find("td.duration").Text()
-		name := s.Find("td.event a.title-search").Text()
-		desc, exists := s.Find("a.data-ace").Attr("data-ace")
+	var fetched []model.KontestModel
+	for _, source := range enabled {
+		fetched = append(fetched, s.sourceResults[source.Name()]...)
+	}
 
-		println(startTime, duration, name, desc, exists)
+	// Merge contests reported by more than one source
+	kontests := s.merger.Merge(fetched)
+	log.Printf("Merged %d fetched contests into %d contests.", len(fetched), len(kontests))
 
-		if !exists {
-			log.Println("data-ace attribute not found for contest:", name)
-			return
-		}
+	previous := s.history.Load().current
+	kontests = assignIdentities(previous, kontests)
 
-		// Unmarshal the JSON data (handling HTML entities)
-		desc = strings.ReplaceAll(desc, "&quot;", "\"")
-		var dataAce struct {
-			Title    string `json:"title"`
-			Desc     string `json:"desc"`
-			Location string `json:"location"`
-			Time     struct {
-				Start string `json:"start"`
-				End   string `json:"end"`
-			} `json:"time"`
-		}
+	// Persisting fills in the IDs of contests that are already stored, so it happens before publishing
+	persistErr := s.persist(ctx, kontests)
 
-		if err := json.Unmarshal([]byte(desc), &dataAce); err != nil {
-			log.Printf("Failed to unmarshal JSON: %v\n", err)
-			return
-		}
+	// Publish the new snapshot, refreshes are serialized so the version cannot be taken twice
+	snapshot := newKontestSnapshot(previous.Version+1, time.Now(), kontests)
+	s.history.Store(s.history.Load().publish(snapshot, s.snapshotRetention))
 
-		// Extract URL from the desc
-		url := strings.Replace(dataAce.Desc, "url: ", "", 1)
+	s.statusMutex.Lock()
+	s.status.LastUpdatedAt = snapshot.UpdatedAt
+	if persistErr != nil {
+		log.Printf("Failed to persist contests: %v", persistErr)
+		s.status.PersistenceError = persistErr.Error()
+	} else {
+		s.status.LastPersistedAt = snapshot.UpdatedAt
+		s.status.PersistenceError = ""
+	}
+	s.statusMutex.Unlock()
 
-		kontest := model.NewKontestModel(name, url, dataAce.Time.Start, dataAce.Time.End, dataAce.Location, "")
+	return errors.Join(append(sourceErrs, persistErr)...)
+}
 
-		kontestModels = append(kontestModels, *kontest)
-	})
+// assignIdentities gives every contest a unique natural key and reuses the ID, first-seen time and
+// natural key of the matching contest in the previous snapshot, so contests keep their identity
+// even if they cannot be persisted. A contest matches if any of its identity keys does, so a
+// contest keeps its ID when another source starts or stops reporting it. A contest whose key is
+// already taken by another contest gets a key that includes its start time instead.
+func assignIdentities(previous *KontestSnapshot, kontests []model.KontestModel) []model.KontestModel {
+	now := time.Now()
+	taken := make(map[string]bool, len(kontests))
+	claimed := make(map[uuid.UUID]bool, len(kontests))
+
+	// findPrevious returns the unclaimed contest of the previous snapshot with one of the keys
+	findPrevious := func(keys ...string) (model.KontestModel, bool) {
+		for _, key := range keys {
+			if cached, ok := previous.FindByNaturalKey(key); ok && !claimed[cached.ID] {
+				return cached, true
+			}
+		}
+		return model.KontestModel{}, false
+	}
 
-	log.Println("Parsed contests successfully.")
-	return kontestModels
-}
+	for i := range kontests {
+		kontest := &kontests[i]
+		keys := kontest.IdentityKeys
+		if len(keys) == 0 {
+			keys = []string{kontest.ComputeNaturalKey()}
+		}
 
-func (s *KontestService) saveKontestsToDB(kontests []model.KontestModel) {
-	for _, kontest := range kontests {
-		s.kontestRepo.Save(kontest)
+		cached, found := findPrevious(keys...)
+		if found && !taken[cached.NaturalKey] {
+			kontest.NaturalKey = cached.NaturalKey
+		} else {
+			found = false
+			key := kontest.ComputeNaturalKey()
+			kontest.NaturalKey = uniqueNaturalKey(taken, key, kontest.StartTime)
+			if kontest.NaturalKey != key {
+				log.Printf("Natural key of %q is already taken, using %s", kontest.Name, kontest.NaturalKey)
+				cached, found = findPrevious(kontest.NaturalKey)
+			}
+		}
+		taken[kontest.NaturalKey] = true
+
+		if found {
+			claimed[cached.ID] = true
+			kontest.ID = cached.ID
+			kontest.FirstSeenAt = cached.FirstSeenAt
+		} else {
+			kontest.FirstSeenAt = now
+		}
+		kontest.UpdatedAt = now
 	}
+	return kontests
 }
 
-// GetContests retrieves a paginated list of contests
-func (s *KontestService) GetContests(page, perPage int) ([]map[string]string, error) {
-	s.fetchHtmlIfNeeded()
-
-	// Calculate offset for pagination
-	offset := (page - 1) * perPage
+// uniqueNaturalKey returns the key if it is not taken yet, and otherwise the key extended with
+// the start time, numbered if that is taken as well
+func uniqueNaturalKey(taken map[string]bool, key string, startTime time.Time) string {
+	if !taken[key] {
+		return key
+	}
 
-	// Limit the result size to perPage
-	if offset >= len(s.kontestsCache) {
-		return []map[string]string{}, nil // Return an empty result if the offset is out of range
+	key += "|" + startTime.UTC().Format(time.RFC3339)
+	unique := key
+	for n := 2; taken[unique]; n++ {
+		unique = fmt.Sprintf("%s|%d", key, n)
 	}
+	return unique
+}
 
-	// Convert to a slice of maps for easier JSON serialization
-	result := make([]map[string]string, 0, perPage) // Initialize with capacity of perPage
-	for i := offset; i < offset+perPage && i < len(s.kontestsCache); i++ {
-		contest := s.kontestsCache[i]
-		result = append(result, map[string]string{
-			"id":                contest.ID.String(),
-			"name":              contest.Name,
-			"url":               contest.URL,
-			"start_time":        contest.StartTime,
-			"end_time":          contest.EndTime,
-			"location":          contest.Location,
-			"status":            contest.Status,
-			"site_abbreviation": string(contest.SiteAbbreviation), // Assuming it's a string type
-		})
+// persist replaces the stored contests and records the update in the metadata.
+func (s *KontestService) persist(ctx context.Context, kontests []model.KontestModel) error {
+	// Replace the stored contests in one transaction so readers never see a partial snapshot
+	if err := s.kontestRepo.ReplaceAll(ctx, kontests); err != nil {
+		return err
 	}
 
-	return result, nil
+	// Update metadata
+	return s.metadataRepo.Save(ctx, model.NewMetadata())
 }
 
-// GetContestsOfSpecificSites retrieves contests for specific sites with pagination
-func (s *KontestService) GetContestsOfSpecificSites(sites []string, page, perPage int) ([]map[string]string, error) {
-	s.fetchHtmlIfNeeded() // Ensure contests are fetched if needed
+// fetchFromSources fetches the given sources concurrently and stores the contests of each
+// successful fetch in sourceResults. It returns a *SourceError for every source that failed,
+// or the context's error without storing anything if the context was cancelled.
+func (s *KontestService) fetchFromSources(ctx context.Context, toFetch []sources.ContestSource) ([]error, error) {
+	results := make([][]model.KontestModel, len(toFetch))
+	errs := make([]error, len(toFetch))
+
+	var wg sync.WaitGroup
+	for i, source := range toFetch {
+		wg.Add(1)
+		go func(i int, source sources.ContestSource) {
+			defer wg.Done()
+			results[i], errs[i] = source.Fetch(ctx)
+		}(i, source)
+	}
+	wg.Wait()
 
-	// Calculate offset for pagination
-	offset := (page - 1) * perPage
+	// Sources interrupted by the cancellation did not fail, so their status is left untouched
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-	var contests []model.KontestModel
+	attemptedAt := time.Now()
 
-	// Filter contests from the cache based on specific sites
-	for _, contest := range s.kontestsCache {
-		for _, site := range sites {
-			if string(contest.SiteAbbreviation) == site {
-				contests = append(contests, contest)
-				break
-			}
-		}
-	}
+	var sourceErrs []error
+	for i, source := range toFetch {
+		s.recordSourceResult(source.Name(), attemptedAt, errs[i])
 
-	// Apply pagination
-	start := offset
-	end := offset + perPage
-	if start >= len(contests) {
-		return []map[string]string{}, nil // Return an empty result if the offset is beyond available contests
-	}
-	if end > len(contests) {
-		end = len(contests) // Adjust end if it exceeds the slice length
-	}
+		if errs[i] != nil {
+			log.Printf("Failed to fetch contests from %s: %v", source.Name(), errs[i])
+			sourceErrs = append(sourceErrs, &SourceError{Source: source.Name(), Err: errs[i]})
+			continue
+		}
 
-	// Convert to a slice of maps for easier JSON serialization
-	result := make([]map[string]string, end-start)
-	for i, contest := range contests[start:end] {
-		result[i] = map[string]string{
-			"id":                contest.ID.String(),
-			"name":              contest.Name,
-			"url":               contest.URL,
-			"start_time":        contest.StartTime,
-			"end_time":          contest.EndTime,
-			"location":          contest.Location,
-			"status":            contest.Status,
-			"site_abbreviation": string(contest.SiteAbbreviation),
+		log.Printf("Fetched %d contests from %s.", len(results[i]), source.Name())
+		for j := range results[i] {
+			results[i][j].Source = source.Name()
 		}
+		s.sourceResults[source.Name()] = results[i]
 	}
 
-	return result, nil
+	return sourceErrs, nil
 }
 
-// Method to check if an update is needed (this should be implemented based on your logic)
-func (s *KontestService) shouldUpdate() bool {
-	return time.Since(s.lastUpdatedAt) >= updateInterval
-}
+// ErrKontestNotFound is returned when no contest has the requested ID
+var ErrKontestNotFound = errors.New("contest not found")
+
+// FindByID returns the contest with the given ID from the snapshot currently served. Contests that
+// are stored but not in the snapshot, such as those saved while the snapshot could not be loaded,
+// are looked up in the repository. It returns ErrKontestNotFound if there is no such contest.
+func (s *KontestService) FindByID(ctx context.Context, id uuid.UUID) (*model.KontestModel, error) {
+	if kontest, ok := s.history.Load().current.FindByID(id); ok {
+		return &kontest, nil
+	}
 
-// Try to acquire the lock for updating
-func (s *KontestService) tryUpdate() bool {
-	if s.isUpdating.TryLock() {
-		return true
+	kontest, err := s.kontestRepo.FindByID(ctx, id)
+	if errors.Is(err, repository.ErrNotFound) {
+		return nil, ErrKontestNotFound
+	}
+	if err != nil {
+		return nil, err
 	}
-	return false
+	return &kontest, nil
 }
 
-func (s *KontestService) fetchHtmlIfNeeded() {
-	if s.shouldUpdate() {
-		// Try to lock for updating
-		if !s.tryUpdate() {
-			log.Println("Update is already in progress by another thread.")
-			return
-		}
+// Query runs the query against the snapshot of the contests currently served, or the snapshot
+// with the query's version. It returns ErrSnapshotExpired if that snapshot is no longer retained,
+// and fails if the context is already done.
+func (s *KontestService) Query(ctx context.Context, query KontestQuery) (*KontestQueryResult, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
 
-		// Perform the fetch operation
-		defer s.isUpdating.Unlock() // Ensure that we unlock even if an error occurs
-		s.fetchHtml()               // This will perform the fetching and updating logic
-	} else {
-		log.Println("Update is not required.")
+	now := time.Now()
+	history := s.history.Load()
+
+	snapshot := history.current
+	if query.Version != 0 {
+		var ok bool
+		if snapshot, ok = history.find(query.Version, now, s.snapshotRetention); !ok {
+			return nil, ErrSnapshotExpired
+		}
 	}
-}
 
-func (s *KontestService) PurgeMetadata() {
-	// a very long ago time
-	s.lastUpdatedAt = time.Date(2000, 1, 1, 0, 0, 0, 0, time.UTC)
-	fmt.Println(s.lastUpdatedAt)
+	if query.At.IsZero() {
+		query.At = now
+	}
+	return snapshot.Query(query, query.At), nil
 }
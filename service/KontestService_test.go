@@ -0,0 +1,265 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"kontest-api/model"
+	"kontest-api/repository/impl"
+	"kontest-api/sources"
+	"slices"
+	"testing"
+	"time"
+)
+
+// fakeSource is a contest source returning fixed contests, or failing with err
+type fakeSource struct {
+	name     string
+	kontests []model.KontestModel
+	err      error
+}
+
+func (f *fakeSource) Name() string {
+	return f.name
+}
+
+func (f *fakeSource) Fetch(ctx context.Context) ([]model.KontestModel, error) {
+	if f.err != nil {
+		return nil, f.err
+	}
+	return slices.Clone(f.kontests), nil
+}
+
+// newTestService creates a service backed by in-memory repositories, refreshing the given sources
+func newTestService(t *testing.T, kontestRepo *impl.InMemoryKontestRepository, fakes ...*fakeSource) *KontestService {
+	t.Helper()
+	registry := sources.NewRegistry()
+	for _, fake := range fakes {
+		registry.Register(fake)
+	}
+	merger := NewKontestMerger([]string{"codeforces", "atcoder"}, 15*time.Minute)
+	return NewKontestService(context.Background(), kontestRepo, impl.NewInMemoryMetadataRepository(), registry, merger, time.Minute)
+}
+
+func newTestKontest(name, url string, start time.Time, location string) model.KontestModel {
+	return *model.NewKontestModel(name, url, start, start.Add(2*time.Hour), location)
+}
+
+// kontestsByURL returns the contests of the current snapshot keyed by URL
+func kontestsByURL(s *KontestService) map[string]model.KontestModel {
+	result, _ := s.Query(context.Background(), KontestQuery{})
+	byURL := make(map[string]model.KontestModel, len(result.Contests))
+	for _, kontest := range result.Contests {
+		byURL[kontest.URL] = kontest
+	}
+	return byURL
+}
+
+func TestRefreshSourcesPublishesAndPersistsContests(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	codeforces := &fakeSource{name: "codeforces", kontests: []model.KontestModel{
+		newTestKontest("Codeforces Round 1", "https://codeforces.com/contest/1", start, "codeforces.com"),
+		newTestKontest("Codeforces Round 2", "https://codeforces.com/contest/2", start.Add(time.Hour), "codeforces.com"),
+	}}
+	atcoder := &fakeSource{name: "atcoder", kontests: []model.KontestModel{
+		newTestKontest("AtCoder Beginner Contest 1", "https://atcoder.jp/contests/abc001", start, "atcoder.jp"),
+	}}
+
+	repo := impl.NewInMemoryKontestRepository()
+	s := newTestService(t, repo, codeforces, atcoder)
+	initial := s.Snapshot()
+
+	if err := s.RefreshSources(context.Background(), []string{"codeforces", "atcoder"}); err != nil {
+		t.Fatalf("RefreshSources failed: %v", err)
+	}
+
+	if s.Snapshot().Len() != 3 || s.Snapshot().Version != initial.Version+1 {
+		t.Errorf("snapshot has %d contests at version %d, want 3 at version %d", s.Snapshot().Len(), s.Snapshot().Version, initial.Version+1)
+	}
+	stored, err := repo.FindAll(context.Background())
+	if err != nil || len(stored) != 3 {
+		t.Errorf("repository has %d contests (%v), want 3", len(stored), err)
+	}
+	if kontest := kontestsByURL(s)["https://atcoder.jp/contests/abc001"]; kontest.Source != "atcoder" {
+		t.Errorf("Source = %q, want atcoder", kontest.Source)
+	}
+	if status := s.Status(); !status.Healthy() || status.LastPersistedAt.IsZero() {
+		t.Errorf("status = %+v, want healthy and persisted", status)
+	}
+}
+
+func TestRefreshSourcesKeepsIDsAcrossRefreshes(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	codeforces := &fakeSource{name: "codeforces", kontests: []model.KontestModel{
+		newTestKontest("Codeforces Round 1", "https://codeforces.com/contest/1", start, "codeforces.com"),
+	}}
+
+	repo := impl.NewInMemoryKontestRepository()
+	s := newTestService(t, repo, codeforces)
+	if err := s.RefreshSources(context.Background(), []string{"codeforces"}); err != nil {
+		t.Fatalf("first RefreshSources failed: %v", err)
+	}
+	first := kontestsByURL(s)["https://codeforces.com/contest/1"]
+
+	// The contest is renamed and moved, and sources assign a fresh ID on every fetch
+	codeforces.kontests = []model.KontestModel{
+		newTestKontest("Codeforces Round 1 (Div. 2)", "https://codeforces.com/contest/1", start.Add(time.Hour), "codeforces.com"),
+	}
+	if err := s.RefreshSources(context.Background(), []string{"codeforces"}); err != nil {
+		t.Fatalf("second RefreshSources failed: %v", err)
+	}
+	second := kontestsByURL(s)["https://codeforces.com/contest/1"]
+
+	if second.ID != first.ID {
+		t.Errorf("ID changed from %s to %s", first.ID, second.ID)
+	}
+	if !second.FirstSeenAt.Equal(first.FirstSeenAt) {
+		t.Errorf("FirstSeenAt changed from %v to %v", first.FirstSeenAt, second.FirstSeenAt)
+	}
+	if second.Name != "Codeforces Round 1 (Div. 2)" {
+		t.Errorf("Name = %q, want the renamed contest", second.Name)
+	}
+
+	// A restarted service loads the stored contests and keeps their IDs as well
+	restarted := newTestService(t, repo, codeforces)
+	if err := restarted.RefreshSources(context.Background(), []string{"codeforces"}); err != nil {
+		t.Fatalf("RefreshSources after restart failed: %v", err)
+	}
+	if third := kontestsByURL(restarted)["https://codeforces.com/contest/1"]; third.ID != first.ID {
+		t.Errorf("ID changed from %s to %s after a restart", first.ID, third.ID)
+	}
+}
+
+func TestRefreshSourcesKeepsContestsOfFailedSource(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	codeforces := &fakeSource{name: "codeforces", kontests: []model.KontestModel{
+		newTestKontest("Codeforces Round 1", "https://codeforces.com/contest/1", start, "codeforces.com"),
+	}}
+	atcoder := &fakeSource{name: "atcoder", kontests: []model.KontestModel{
+		newTestKontest("AtCoder Beginner Contest 1", "https://atcoder.jp/contests/abc001", start, "atcoder.jp"),
+	}}
+
+	repo := impl.NewInMemoryKontestRepository()
+	s := newTestService(t, repo, codeforces, atcoder)
+	if err := s.RefreshSources(context.Background(), []string{"codeforces", "atcoder"}); err != nil {
+		t.Fatalf("first RefreshSources failed: %v", err)
+	}
+	atcoderID := kontestsByURL(s)["https://atcoder.jp/contests/abc001"].ID
+
+	codeforces.kontests = append(codeforces.kontests,
+		newTestKontest("Codeforces Round 2", "https://codeforces.com/contest/2", start.Add(time.Hour), "codeforces.com"))
+	atcoder.err = errors.New("atcoder is down")
+
+	err := s.RefreshSources(context.Background(), []string{"codeforces", "atcoder"})
+	var sourceErr *SourceError
+	if !errors.As(err, &sourceErr) || sourceErr.Source != "atcoder" {
+		t.Fatalf("RefreshSources error = %v, want a SourceError for atcoder", err)
+	}
+
+	byURL := kontestsByURL(s)
+	if len(byURL) != 3 {
+		t.Fatalf("snapshot has %d contests, want 3: %v", len(byURL), byURL)
+	}
+	if kontest, ok := byURL["https://atcoder.jp/contests/abc001"]; !ok || kontest.ID != atcoderID {
+		t.Errorf("contest of the failed source was not kept with its ID: %+v", kontest)
+	}
+	stored, err := repo.FindAll(context.Background())
+	if err != nil || len(stored) != 3 {
+		t.Errorf("repository has %d contests (%v), want 3", len(stored), err)
+	}
+	if s.Status().Healthy() {
+		t.Error("status is healthy although a source failed")
+	}
+}
+
+func TestQueryRejectsVersionOfPreviousProcess(t *testing.T) {
+	repo := impl.NewInMemoryKontestRepository()
+	previous := newTestService(t, repo)
+	restarted := newTestService(t, repo)
+
+	if restarted.Snapshot().Version == previous.Snapshot().Version {
+		t.Fatalf("restarted service reuses version %d", previous.Snapshot().Version)
+	}
+	_, err := restarted.Query(context.Background(), KontestQuery{Version: previous.Snapshot().Version, At: time.Now()})
+	if !errors.Is(err, ErrSnapshotExpired) {
+		t.Errorf("Query with the version of a previous process = %v, want ErrSnapshotExpired", err)
+	}
+}
+
+func TestRefreshSourcesKeepsIDWhenAnotherSourceReportsContest(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	// clist links Codeforces rounds under /contests/, the Codeforces API under /contest/
+	clist := &fakeSource{name: "clist-api", kontests: []model.KontestModel{
+		newTestKontest("Codeforces Round 956 (Div. 2)", "https://codeforces.com/contests/1983", start, "codeforces.com"),
+	}}
+	codeforces := &fakeSource{name: "codeforces"}
+
+	repo := impl.NewInMemoryKontestRepository()
+	s := newTestService(t, repo, clist, codeforces)
+	if err := s.RefreshSources(context.Background(), []string{"clist-api"}); err != nil {
+		t.Fatalf("RefreshSources of clist-api failed: %v", err)
+	}
+	first := kontestsByURL(s)["https://codeforces.com/contests/1983"]
+
+	// Codeforces starts listing the round and wins the merge
+	codeforces.kontests = []model.KontestModel{
+		newTestKontest("Codeforces Round 956 (Div. 2)", "https://codeforces.com/contest/1983", start, "codeforces.com"),
+	}
+	if err := s.RefreshSources(context.Background(), []string{"codeforces"}); err != nil {
+		t.Fatalf("RefreshSources of codeforces failed: %v", err)
+	}
+	merged := kontestsByURL(s)["https://codeforces.com/contest/1983"]
+	if merged.ID != first.ID || !merged.FirstSeenAt.Equal(first.FirstSeenAt) {
+		t.Errorf("merged contest has ID %s first seen at %v, want %s first seen at %v", merged.ID, merged.FirstSeenAt, first.ID, first.FirstSeenAt)
+	}
+
+	// The round keeps its ID after a restart, and when clist stops listing it
+	restarted := newTestService(t, repo, clist, codeforces)
+	if err := restarted.RefreshSources(context.Background(), []string{"clist-api", "codeforces"}); err != nil {
+		t.Fatalf("RefreshSources after restart failed: %v", err)
+	}
+	if kontest := kontestsByURL(restarted)["https://codeforces.com/contest/1983"]; kontest.ID != first.ID {
+		t.Errorf("ID changed from %s to %s after a restart", first.ID, kontest.ID)
+	}
+	clist.kontests = nil
+	if err := restarted.RefreshSources(context.Background(), []string{"clist-api"}); err != nil {
+		t.Fatalf("RefreshSources without the clist entry failed: %v", err)
+	}
+	if kontest := kontestsByURL(restarted)["https://codeforces.com/contest/1983"]; kontest.ID != first.ID {
+		t.Errorf("ID changed from %s to %s once only codeforces lists the round", first.ID, kontest.ID)
+	}
+	if stored, err := repo.FindAll(context.Background()); err != nil || len(stored) != 1 || stored[0].ID != first.ID {
+		t.Errorf("repository has %+v (%v), want only the round with ID %s", stored, err, first.ID)
+	}
+}
+
+func TestRefreshSourcesKeepsContestsWithCollidingKeys(t *testing.T) {
+	start := time.Now().Add(24 * time.Hour).Truncate(time.Second)
+	// Both rounds share the URL of the contest page, but start a day apart
+	codeforces := &fakeSource{name: "codeforces", kontests: []model.KontestModel{
+		newTestKontest("Codeforces Round 1", "https://codeforces.com/contests", start, "codeforces.com"),
+		newTestKontest("Codeforces Round 2", "https://codeforces.com/contests", start.Add(24*time.Hour), "codeforces.com"),
+	}}
+
+	repo := impl.NewInMemoryKontestRepository()
+	s := newTestService(t, repo, codeforces)
+	ids := make(map[string]string)
+	for refresh := range 2 {
+		if err := s.RefreshSources(context.Background(), []string{"codeforces"}); err != nil {
+			t.Fatalf("RefreshSources failed: %v", err)
+		}
+
+		result, _ := s.Query(context.Background(), KontestQuery{})
+		if len(result.Contests) != 2 {
+			t.Fatalf("refresh %d: snapshot has %d contests, want both rounds", refresh, len(result.Contests))
+		}
+		for _, kontest := range result.Contests {
+			if id, ok := ids[kontest.Name]; ok && id != kontest.ID.String() {
+				t.Errorf("refresh %d: ID of %s changed from %s to %s", refresh, kontest.Name, id, kontest.ID)
+			}
+			ids[kontest.Name] = kontest.ID.String()
+		}
+	}
+	if stored, err := repo.FindAll(context.Background()); err != nil || len(stored) != 2 {
+		t.Errorf("repository has %d contests (%v), want both rounds", len(stored), err)
+	}
+}
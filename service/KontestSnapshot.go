@@ -0,0 +1,166 @@
+package service
+
+import (
+	"github.com/google/uuid"
+	"kontest-api/model"
+	"sort"
+	"strings"
+	"time"
+)
+
+// KontestSnapshot is an immutable set of contests published by a refresh, together with the
+// indexes built over them. A snapshot is never modified once it has been published, so it can
+// be read concurrently without locking; a refresh publishes a new snapshot instead.
+type KontestSnapshot struct {
+	// Version increases by one with every published snapshot, starting from a value unique to the process
+	Version uint64
+
+	// UpdatedAt is the time the contests were last refreshed
+	UpdatedAt time.Time
+
+	// contests are ordered by start time, end time and site
+	contests []model.KontestModel
+
+	// bySite maps each site abbreviation to the positions of its contests, in contest order
+	bySite map[string][]int
+
+	// byEnd holds the positions of the contests ordered by end time, so the finished contests
+	// at any given time are a prefix of it
+	byEnd []int
+
+	// byID maps each contest ID to the position of its contest
+	byID map[uuid.UUID]int
+
+	// byNaturalKey maps each natural key, and each identity key of a merged contest, to the
+	// position of its contest. Natural keys take precedence over identity keys.
+	byNaturalKey map[string]int
+
+	// lowerNames holds the lowercase name of every contest for case-insensitive search
+	lowerNames []string
+}
+
+// newKontestSnapshot sorts the contests and builds the snapshot's indexes over them.
+// The snapshot takes ownership of the slice, which must not be used by the caller afterwards.
+func newKontestSnapshot(version uint64, updatedAt time.Time, kontests []model.KontestModel) *KontestSnapshot {
+	sort.SliceStable(kontests, func(i, j int) bool {
+		// Sort by StartTime
+		if !kontests[i].StartTime.Equal(kontests[j].StartTime) {
+			return kontests[i].StartTime.Before(kontests[j].StartTime)
+		}
+
+		// Sort by EndTime
+		if !kontests[i].EndTime.Equal(kontests[j].EndTime) {
+			return kontests[i].EndTime.Before(kontests[j].EndTime)
+		}
+
+		// Finally sort by SiteAbbreviation
+		return kontests[i].SiteAbbreviation < kontests[j].SiteAbbreviation
+	})
+
+	snapshot := &KontestSnapshot{
+		Version:      version,
+		UpdatedAt:    updatedAt,
+		contests:     kontests,
+		bySite:       make(map[string][]int),
+		byEnd:        make([]int, len(kontests)),
+		byID:         make(map[uuid.UUID]int, len(kontests)),
+		byNaturalKey: make(map[string]int, len(kontests)),
+		lowerNames:   make([]string, len(kontests)),
+	}
+	for i, kontest := range kontests {
+		snapshot.bySite[kontest.SiteAbbreviation] = append(snapshot.bySite[kontest.SiteAbbreviation], i)
+		snapshot.byEnd[i] = i
+		snapshot.byID[kontest.ID] = i
+		snapshot.lowerNames[i] = strings.ToLower(kontest.Name)
+		if kontest.NaturalKey != "" {
+			snapshot.byNaturalKey[kontest.NaturalKey] = i
+		}
+	}
+	for i, kontest := range kontests {
+		for _, key := range kontest.IdentityKeys {
+			if _, ok := snapshot.byNaturalKey[key]; !ok {
+				snapshot.byNaturalKey[key] = i
+			}
+		}
+	}
+	sort.SliceStable(snapshot.byEnd, func(i, j int) bool {
+		return kontests[snapshot.byEnd[i]].EndTime.Before(kontests[snapshot.byEnd[j]].EndTime)
+	})
+	return snapshot
+}
+
+// Len returns the number of contests in the snapshot.
+func (s *KontestSnapshot) Len() int {
+	return len(s.contests)
+}
+
+// FindByID returns the contest with the given ID, if the snapshot has one.
+func (s *KontestSnapshot) FindByID(id uuid.UUID) (model.KontestModel, bool) {
+	i, ok := s.byID[id]
+	if !ok {
+		return model.KontestModel{}, false
+	}
+	return s.contests[i], true
+}
+
+// FindByNaturalKey returns the contest with the given natural or identity key, if the snapshot has one.
+func (s *KontestSnapshot) FindByNaturalKey(key string) (model.KontestModel, bool) {
+	i, ok := s.byNaturalKey[key]
+	if !ok {
+		return model.KontestModel{}, false
+	}
+	return s.contests[i], true
+}
+
+// maxSupersededSnapshots bounds the number of superseded snapshots that are retained, however
+// often the contests are refreshed
+const maxSupersededSnapshots = 10
+
+// snapshotHistory is the current snapshot together with the recently superseded ones, so that
+// cursors into a superseded snapshot keep returning consistent pages for a while. Like the
+// snapshots it holds, a history is immutable; publishing a snapshot creates a new one.
+type snapshotHistory struct {
+	current *KontestSnapshot
+
+	// superseded holds the retained superseded snapshots, newest first
+	superseded []supersededSnapshot
+}
+
+// supersededSnapshot is a snapshot together with the time it was replaced
+type supersededSnapshot struct {
+	snapshot     *KontestSnapshot
+	supersededAt time.Time
+}
+
+// publish returns a new history with the snapshot as the current one. Superseded snapshots are
+// retained until retention has passed since they were replaced.
+func (h *snapshotHistory) publish(snapshot *KontestSnapshot, retention time.Duration) *snapshotHistory {
+	next := &snapshotHistory{current: snapshot}
+	if h == nil || retention <= 0 {
+		return next
+	}
+
+	now := snapshot.UpdatedAt
+	next.superseded = append(next.superseded, supersededSnapshot{snapshot: h.current, supersededAt: now})
+	for _, old := range h.superseded {
+		if len(next.superseded) == maxSupersededSnapshots || now.Sub(old.supersededAt) >= retention {
+			break
+		}
+		next.superseded = append(next.superseded, old)
+	}
+	return next
+}
+
+// find returns the snapshot with the given version if it is current or was superseded less than
+// retention before now.
+func (h *snapshotHistory) find(version uint64, now time.Time, retention time.Duration) (*KontestSnapshot, bool) {
+	if h.current.Version == version {
+		return h.current, true
+	}
+	for _, old := range h.superseded {
+		if old.snapshot.Version == version {
+			return old.snapshot, now.Sub(old.supersededAt) < retention
+		}
+	}
+	return nil, false
+}
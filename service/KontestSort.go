@@ -0,0 +1,65 @@
+package service
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+)
+
+// SortField is a contest field the query results can be sorted by.
+type SortField string
+
+// List of all sort fields.
+const (
+	SortByStartTime SortField = "start_time"
+	SortByEndTime   SortField = "end_time"
+	SortByDuration  SortField = "duration"
+	SortByName      SortField = "name"
+	SortBySite      SortField = "site"
+)
+
+// GetAllSortFields returns a list of all sort fields.
+func GetAllSortFields() []SortField {
+	return []SortField{SortByStartTime, SortByEndTime, SortByDuration, SortByName, SortBySite}
+}
+
+// SortKey orders the query results by a field, ascending unless Descending is set.
+type SortKey struct {
+	Field      SortField
+	Descending bool
+}
+
+// sortPositions sorts the positions of contests by the keys, in order of precedence. Contests that
+// compare equal on every key keep the snapshot's order of start time, end time and site.
+func (s *KontestSnapshot) sortPositions(positions []int, keys []SortKey) {
+	slices.SortFunc(positions, func(a, b int) int {
+		for _, key := range keys {
+			c := s.compareBy(key.Field, a, b)
+			if key.Descending {
+				c = -c
+			}
+			if c != 0 {
+				return c
+			}
+		}
+		return cmp.Compare(a, b)
+	})
+}
+
+// compareBy compares the contests at the positions a and b by the field. Names compare case-insensitively.
+func (s *KontestSnapshot) compareBy(field SortField, a, b int) int {
+	kontestA, kontestB := &s.contests[a], &s.contests[b]
+	switch field {
+	case SortByStartTime:
+		return kontestA.StartTime.Compare(kontestB.StartTime)
+	case SortByEndTime:
+		return kontestA.EndTime.Compare(kontestB.EndTime)
+	case SortByDuration:
+		return cmp.Compare(kontestA.EndTime.Sub(kontestA.StartTime), kontestB.EndTime.Sub(kontestB.StartTime))
+	case SortByName:
+		return strings.Compare(s.lowerNames[a], s.lowerNames[b])
+	case SortBySite:
+		return strings.Compare(kontestA.SiteAbbreviation, kontestB.SiteAbbreviation)
+	}
+	return 0
+}
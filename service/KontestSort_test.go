@@ -0,0 +1,107 @@
+package service
+
+import (
+	"kontest-api/model"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestKontestSnapshotQuerySort(t *testing.T) {
+	now := time.Date(2024, 7, 6, 12, 0, 0, 0, time.UTC)
+	kontest := func(name, location string, start, duration time.Duration) model.KontestModel {
+		return *model.NewKontestModel(name, "", now.Add(start), now.Add(start+duration), location)
+	}
+	// In snapshot order: by start time, end time and site
+	snapshot := newKontestSnapshot(1, now, []model.KontestModel{
+		kontest("beta Round", "codeforces.com", 0, 2*time.Hour),
+		kontest("Alpha Cup", "atcoder.jp", 0, 2*time.Hour),
+		kontest("alpha Cup", "leetcode.com", time.Hour, 90*time.Minute),
+		kontest("Gamma Round", "codeforces.com", time.Hour, 3*time.Hour),
+		kontest("Delta Round", "atcoder.jp", 2*time.Hour, time.Hour),
+	})
+
+	tests := []struct {
+		name string
+		sort []SortKey
+		want []string
+	}{
+		{
+			name: "no sort keeps the snapshot order",
+			want: []string{"Alpha Cup", "beta Round", "alpha Cup", "Gamma Round", "Delta Round"},
+		},
+		{
+			name: "start time descending",
+			sort: []SortKey{{Field: SortByStartTime, Descending: true}},
+			want: []string{"Delta Round", "alpha Cup", "Gamma Round", "Alpha Cup", "beta Round"},
+		},
+		{
+			name: "start time descending, then site",
+			sort: []SortKey{{Field: SortByStartTime, Descending: true}, {Field: SortBySite}},
+			want: []string{"Delta Round", "Gamma Round", "alpha Cup", "Alpha Cup", "beta Round"},
+		},
+		{
+			name: "site descending, then start time descending",
+			sort: []SortKey{{Field: SortBySite, Descending: true}, {Field: SortByStartTime, Descending: true}},
+			want: []string{"alpha Cup", "Gamma Round", "beta Round", "Delta Round", "Alpha Cup"},
+		},
+		{
+			name: "name ignores case and ties keep the snapshot order",
+			sort: []SortKey{{Field: SortByName}},
+			want: []string{"Alpha Cup", "alpha Cup", "beta Round", "Delta Round", "Gamma Round"},
+		},
+		{
+			name: "name descending",
+			sort: []SortKey{{Field: SortByName, Descending: true}},
+			want: []string{"Gamma Round", "Delta Round", "beta Round", "Alpha Cup", "alpha Cup"},
+		},
+		{
+			name: "duration",
+			sort: []SortKey{{Field: SortByDuration}},
+			want: []string{"Delta Round", "alpha Cup", "Alpha Cup", "beta Round", "Gamma Round"},
+		},
+		{
+			name: "duration descending, then name",
+			sort: []SortKey{{Field: SortByDuration, Descending: true}, {Field: SortByName, Descending: true}},
+			want: []string{"Gamma Round", "beta Round", "Alpha Cup", "alpha Cup", "Delta Round"},
+		},
+		{
+			name: "end time",
+			sort: []SortKey{{Field: SortByEndTime}},
+			want: []string{"Alpha Cup", "beta Round", "alpha Cup", "Delta Round", "Gamma Round"},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var names []string
+			for _, kontest := range snapshot.Query(KontestQuery{Sort: tt.sort}, now).Contests {
+				names = append(names, kontest.Name)
+			}
+			if !slices.Equal(names, tt.want) {
+				t.Errorf("sorted contests = %q, want %q", names, tt.want)
+			}
+		})
+	}
+}
+
+func TestKontestSnapshotQuerySortPaginates(t *testing.T) {
+	now := time.Date(2024, 7, 6, 12, 0, 0, 0, time.UTC)
+	var kontests []model.KontestModel
+	for i, name := range []string{"e", "c", "a", "d", "b"} {
+		start := now.Add(time.Duration(i) * time.Hour)
+		kontests = append(kontests, *model.NewKontestModel(name, "", start, start.Add(time.Hour), "codeforces.com"))
+	}
+	snapshot := newKontestSnapshot(1, now, kontests)
+
+	// Pages are taken from the sorted results, not sorted one by one
+	var names []string
+	for offset := 0; offset < 5; offset += 2 {
+		result := snapshot.Query(KontestQuery{Sort: []SortKey{{Field: SortByName}}, Offset: offset, Limit: 2}, now)
+		for _, kontest := range result.Contests {
+			names = append(names, kontest.Name)
+		}
+	}
+	if want := []string{"a", "b", "c", "d", "e"}; !slices.Equal(names, want) {
+		t.Errorf("paged contests = %q, want %q", names, want)
+	}
+}
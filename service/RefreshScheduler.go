@@ -0,0 +1,222 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"kontest-api/repository"
+	"kontest-api/sources"
+	"log"
+	"math/rand/v2"
+	"time"
+)
+
+// retryBaseDelay is the delay before the first retry of a source that failed to refresh
+const retryBaseDelay = 30 * time.Second
+
+// ErrSchedulerStopped is returned when a refresh is triggered while the scheduler is not running
+var ErrSchedulerStopped = errors.New("refresh scheduler is not running")
+
+// RefreshScheduler refreshes the contest sources in the background, each on its own interval,
+// so that request handlers only ever read the cached contests.
+type RefreshScheduler struct {
+	service         *KontestService
+	registry        *sources.Registry
+	defaultInterval time.Duration
+	intervals       map[string]time.Duration
+	jitter          time.Duration
+	timeout         time.Duration
+	trigger         chan struct{}
+	done            chan struct{} // Closed when the scheduler stops, nil if it was never started
+}
+
+// NewRefreshScheduler creates a new RefreshScheduler. Sources without an entry in intervals
+// are refreshed every defaultInterval. A random delay of up to jitter is added to every interval
+// so that sources do not all hit their upstream at the same moment. A refresh that takes longer
+// than timeout is cancelled.
+func NewRefreshScheduler(service *KontestService, registry *sources.Registry, defaultInterval time.Duration, intervals map[string]time.Duration, jitter time.Duration, timeout time.Duration) *RefreshScheduler {
+	return &RefreshScheduler{
+		service:         service,
+		registry:        registry,
+		defaultInterval: defaultInterval,
+		intervals:       intervals,
+		jitter:          jitter,
+		timeout:         timeout,
+		trigger:         make(chan struct{}, 1),
+	}
+}
+
+// Start runs the scheduler in a new goroutine until the context is cancelled.
+// Cancelling the context also cancels a running refresh.
+func (r *RefreshScheduler) Start(ctx context.Context) {
+	r.done = make(chan struct{})
+	go func() {
+		defer close(r.done)
+		r.run(ctx)
+	}()
+}
+
+// Wait blocks until the scheduler has stopped after its context was cancelled, or until ctx is done.
+// It returns immediately if the scheduler was never started.
+func (r *RefreshScheduler) Wait(ctx context.Context) error {
+	if r.done == nil {
+		return nil
+	}
+
+	select {
+	case <-r.done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Trigger requests an immediate refresh of every enabled source. It does not block; triggers
+// received while a refresh is pending are coalesced. It fails if the scheduler is not running.
+func (r *RefreshScheduler) Trigger() error {
+	if r.done == nil {
+		return ErrSchedulerStopped
+	}
+	select {
+	case <-r.done:
+		return ErrSchedulerStopped
+	default:
+	}
+
+	select {
+	case r.trigger <- struct{}{}:
+	default:
+	}
+	return nil
+}
+
+func (r *RefreshScheduler) run(ctx context.Context) {
+	// Contests loaded at startup count as fresh until their interval has passed since the last update
+	lastUpdatedAt := r.service.LastUpdatedAt()
+	nextRun := make(map[string]time.Time)
+	failures := make(map[string]int)
+
+	for {
+		enabled := r.registry.Enabled()
+
+		now := time.Now()
+		wait := r.defaultInterval
+		for _, source := range enabled {
+			next, ok := nextRun[source.Name()]
+			if !ok {
+				next = lastUpdatedAt.Add(r.interval(source.Name()))
+				nextRun[source.Name()] = next
+			}
+			wait = min(wait, max(next.Sub(now), 0))
+		}
+
+		timer := time.NewTimer(wait)
+
+		var due []string
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			log.Println("Refresh scheduler stopped.")
+			return
+		case <-r.trigger:
+			timer.Stop()
+			log.Println("Manual refresh triggered.")
+			for _, source := range enabled {
+				due = append(due, source.Name())
+			}
+		case now = <-timer.C:
+			for _, source := range enabled {
+				if !nextRun[source.Name()].After(now) {
+					due = append(due, source.Name())
+				}
+			}
+		}
+
+		if len(due) == 0 {
+			continue
+		}
+
+		log.Printf("Refreshing sources: %v", due)
+		refreshCtx, cancel := context.WithTimeout(ctx, r.timeout)
+		err := r.service.RefreshSources(refreshCtx, due)
+		timedOut := errors.Is(refreshCtx.Err(), context.DeadlineExceeded) && ctx.Err() == nil
+		cancel()
+
+		// A refresh cancelled because the scheduler is stopping is not retried
+		if ctx.Err() != nil {
+			log.Println("Refresh scheduler stopped.")
+			return
+		}
+
+		failed := failedSources(err, due)
+		if timedOut {
+			log.Printf("Refresh did not finish within %s.", r.timeout)
+			for _, name := range due {
+				failed[name] = true
+			}
+		}
+
+		for _, name := range due {
+			if failed[name] {
+				failures[name]++
+				delay := r.backoff(name, failures[name])
+				log.Printf("Retrying %s in %s after %d consecutive failures.", name, delay, failures[name])
+				nextRun[name] = time.Now().Add(delay)
+				continue
+			}
+
+			failures[name] = 0
+			nextRun[name] = time.Now().Add(r.interval(name) + r.randomJitter())
+		}
+	}
+}
+
+// failedSources returns the names of the sources that need to be retried after a refresh.
+// If the contests could not be persisted, every refreshed source is retried, unless the storage
+// is unavailable altogether, in which case retrying early would not help.
+func failedSources(err error, due []string) map[string]bool {
+	failed := make(map[string]bool)
+	if err == nil {
+		return failed
+	}
+
+	var repositoryErr *repository.RepositoryError
+	if errors.As(err, &repositoryErr) && !errors.Is(repositoryErr, repository.ErrUnavailable) {
+		for _, name := range due {
+			failed[name] = true
+		}
+		return failed
+	}
+
+	errs := []error{err}
+	if joined, ok := err.(interface{ Unwrap() []error }); ok {
+		errs = joined.Unwrap()
+	}
+	for _, err := range errs {
+		var sourceErr *SourceError
+		if errors.As(err, &sourceErr) {
+			failed[sourceErr.Source] = true
+		}
+	}
+	return failed
+}
+
+// backoff returns the delay before retrying a source after the given number of consecutive
+// failures. The delay doubles with every failure and never exceeds the source's interval.
+func (r *RefreshScheduler) backoff(name string, failures int) time.Duration {
+	delay := retryBaseDelay << min(failures-1, 16)
+	return min(delay, r.interval(name))
+}
+
+func (r *RefreshScheduler) interval(name string) time.Duration {
+	if interval, ok := r.intervals[name]; ok {
+		return interval
+	}
+	return r.defaultInterval
+}
+
+func (r *RefreshScheduler) randomJitter() time.Duration {
+	if r.jitter <= 0 {
+		return 0
+	}
+	return rand.N(r.jitter)
+}
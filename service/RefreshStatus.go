@@ -0,0 +1,50 @@
+package service
+
+import (
+	"fmt"
+	"time"
+)
+
+// SourceError is returned when a contest source could not be fetched.
+type SourceError struct {
+	Source string // Name of the source that failed
+	Err    error  // Error returned by the source
+}
+
+func (e *SourceError) Error() string {
+	return fmt.Sprintf("source %s: %v", e.Source, e.Err)
+}
+
+func (e *SourceError) Unwrap() error {
+	return e.Err
+}
+
+// SourceStatus describes the outcome of the most recent fetches of a contest source.
+type SourceStatus struct {
+	Name                string    `json:"name"`
+	LastAttemptAt       time.Time `json:"last_attempt_at"`
+	LastSuccessAt       time.Time `json:"last_success_at"`
+	LastError           string    `json:"last_error,omitempty"`
+	ConsecutiveFailures int       `json:"consecutive_failures"`
+}
+
+// RefreshStatus describes the state of the contest cache and the refreshes that feed it.
+type RefreshStatus struct {
+	LastUpdatedAt    time.Time      `json:"last_updated_at"`
+	LastPersistedAt  time.Time      `json:"last_persisted_at"`
+	PersistenceError string         `json:"persistence_error,omitempty"`
+	Sources          []SourceStatus `json:"sources"`
+}
+
+// Healthy reports whether the last persistence attempt and the last fetch of every source succeeded.
+func (r RefreshStatus) Healthy() bool {
+	if r.PersistenceError != "" {
+		return false
+	}
+	for _, source := range r.Sources {
+		if source.ConsecutiveFailures > 0 {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,117 @@
+package sources
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"github.com/PuerkitoBio/goquery"
+	"kontest-api/model"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// atCoderTimeLayout is the layout of the start times shown in the AtCoder contest tables.
+const atCoderTimeLayout = "2006-01-02 15:04:05-0700"
+
+// AtCoderSource scrapes the running and upcoming contest tables on the AtCoder /contests page.
+type AtCoderSource struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewAtCoderSource creates a new instance of AtCoderSource using the given base URL,
+// e.g. "https://atcoder.jp".
+func NewAtCoderSource(client *http.Client, baseURL string) *AtCoderSource {
+	return &AtCoderSource{client: client, baseURL: baseURL}
+}
+
+// Name returns the name of the source.
+func (a *AtCoderSource) Name() string {
+	return "atcoder"
+}
+
+// Fetch downloads the AtCoder contest page and parses the running and upcoming contests.
+func (a *AtCoderSource) Fetch(ctx context.Context) ([]model.KontestModel, error) {
+	doc, err := fetchDocument(ctx, a.client, a.baseURL+"/contests/?lang=en")
+	if err != nil {
+		return nil, err
+	}
+	return a.parseContests(doc)
+}
+
+// parseContests parses the active and upcoming contest tables. AtCoder practically always has
+// upcoming contests, so a page without either table means the layout has changed; it fails
+// instead of reporting that there are no contests.
+func (a *AtCoderSource) parseContests(doc *goquery.Document) ([]model.KontestModel, error) {
+	tables := doc.Find("#contest-table-action, #contest-table-upcoming")
+	if tables.Length() == 0 {
+		return nil, errors.New("failed to parse AtCoder contests: contest tables not found, the page layout may have changed")
+	}
+
+	var kontestModels []model.KontestModel
+
+	rows := tables.Find("tbody tr")
+	rows.Each(func(i int, s *goquery.Selection) {
+		cells := s.Find("td")
+		link := cells.Eq(1).Find("a").First()
+		name := strings.TrimSpace(link.Text())
+		href, exists := link.Attr("href")
+
+		if !exists {
+			log.Println("Contest link not found in AtCoder row:", name)
+			return
+		}
+
+		startTime, err := time.Parse(atCoderTimeLayout, strings.TrimSpace(cells.Eq(0).Find("time").Text()))
+		if err != nil {
+			log.Printf("Failed to parse AtCoder start time for %s: %v", name, err)
+			return
+		}
+
+		duration, err := parseAtCoderDuration(strings.TrimSpace(cells.Eq(2).Text()))
+		if err != nil {
+			log.Printf("Failed to parse AtCoder duration for %s: %v", name, err)
+			return
+		}
+
+		startTime = startTime.UTC()
+		endTime := startTime.Add(duration)
+
+		kontest := model.NewKontestModel(
+			name,
+			"https://atcoder.jp"+href,
+			startTime,
+			endTime,
+			"atcoder.jp",
+		)
+		kontest.SourceContestID = path.Base(href)
+
+		kontestModels = append(kontestModels, *kontest)
+	})
+
+	return kontestModels, nil
+}
+
+// parseAtCoderDuration parses durations in the "hh:mm" format used by AtCoder.
+// Hours may exceed 24 for long contests.
+func parseAtCoderDuration(value string) (time.Duration, error) {
+	hours, minutes, found := strings.Cut(value, ":")
+	if !found {
+		return 0, fmt.Errorf("invalid duration %q", value)
+	}
+
+	h, err := strconv.Atoi(hours)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil {
+		return 0, fmt.Errorf("invalid duration %q: %w", value, err)
+	}
+
+	return time.Duration(h)*time.Hour + time.Duration(m)*time.Minute, nil
+}
@@ -0,0 +1,108 @@
+package sources
+
+import (
+	"github.com/PuerkitoBio/goquery"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestAtCoderParseContests(t *testing.T) {
+	file, err := os.Open("testdata/atcoder_contests.html")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	doc, err := goquery.NewDocumentFromReader(file)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	kontests, err := NewAtCoderSource(nil, "https://atcoder.jp").parseContests(doc)
+	if err != nil {
+		t.Fatalf("parseContests failed: %v", err)
+	}
+
+	// Only the active and upcoming contests are listed, not the permanent and recent ones
+	want := []struct {
+		name, url, id string
+		start, end    time.Time
+	}{
+		{
+			name:  "AtCoder Heuristic Contest 035",
+			url:   "https://atcoder.jp/contests/ahc035",
+			id:    "ahc035",
+			start: time.Date(2024, 7, 5, 6, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 7, 9, 10, 0, 0, 0, time.UTC), // Lasts 100 hours
+		},
+		{
+			name:  "AtCoder Beginner Contest 361",
+			url:   "https://atcoder.jp/contests/abc361",
+			id:    "abc361",
+			start: time.Date(2024, 7, 6, 12, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 7, 6, 13, 40, 0, 0, time.UTC),
+		},
+		{
+			name:  "AtCoder Regular Contest 181",
+			url:   "https://atcoder.jp/contests/arc181",
+			id:    "arc181",
+			start: time.Date(2024, 7, 14, 12, 0, 0, 0, time.UTC),
+			end:   time.Date(2024, 7, 14, 14, 0, 0, 0, time.UTC),
+		},
+	}
+	if len(kontests) != len(want) {
+		t.Fatalf("got %d contests, want %d: %+v", len(kontests), len(want), kontests)
+	}
+	for i, w := range want {
+		got := kontests[i]
+		if got.Name != w.name || got.URL != w.url || got.SourceContestID != w.id {
+			t.Errorf("contest %d = %q %q %q, want %q %q %q", i, got.Name, got.URL, got.SourceContestID, w.name, w.url, w.id)
+		}
+		if !got.StartTime.Equal(w.start) || got.StartTime.Location() != time.UTC {
+			t.Errorf("%s: StartTime = %v, want %v", w.name, got.StartTime, w.start)
+		}
+		if !got.EndTime.Equal(w.end) {
+			t.Errorf("%s: EndTime = %v, want %v", w.name, got.EndTime, w.end)
+		}
+		if got.SiteAbbreviation != "AtCoder" {
+			t.Errorf("%s: SiteAbbreviation = %q, want AtCoder", w.name, got.SiteAbbreviation)
+		}
+	}
+}
+
+func TestAtCoderParseContestsWithoutTables(t *testing.T) {
+	doc, err := goquery.NewDocumentFromReader(strings.NewReader(`<html><body><div id="main-container"><h2>Contest</h2></div></body></html>`))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if kontests, err := NewAtCoderSource(nil, "https://atcoder.jp").parseContests(doc); err == nil {
+		t.Fatalf("parseContests returned %d contests, want an error", len(kontests))
+	}
+}
+
+func TestParseAtCoderDuration(t *testing.T) {
+	tests := []struct {
+		value   string
+		want    time.Duration
+		wantErr bool
+	}{
+		{value: "01:40", want: time.Hour + 40*time.Minute},
+		{value: "100:00", want: 100 * time.Hour},
+		{value: "240:30", want: 240*time.Hour + 30*time.Minute},
+		{value: "1h40m", wantErr: true},
+		{value: "", wantErr: true},
+	}
+	for _, tt := range tests {
+		got, err := parseAtCoderDuration(tt.value)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseAtCoderDuration(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseAtCoderDuration(%q) = %v, want %v", tt.value, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,166 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"kontest-api/model"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+const (
+	// clistAPIPageLimit is the number of contests requested per page
+	clistAPIPageLimit = 100
+
+	// clistAPITimeLayout is the layout of the UTC timestamps used by the clist API
+	clistAPITimeLayout = "2006-01-02T15:04:05"
+)
+
+// ClistAPISource reads contests from the documented clist.by /api/v4/contest/ endpoint.
+type ClistAPISource struct {
+	client   *http.Client
+	baseURL  string
+	username string
+	apiKey   string
+	window   time.Duration
+}
+
+// NewClistAPISource creates a new instance of ClistAPISource using the given base URL,
+// e.g. "https://clist.by", and API credentials. Only contests that have not ended and
+// start within the given window from now are fetched.
+func NewClistAPISource(client *http.Client, baseURL, username, apiKey string, window time.Duration) *ClistAPISource {
+	return &ClistAPISource{
+		client:   client,
+		baseURL:  baseURL,
+		username: username,
+		apiKey:   apiKey,
+		window:   window,
+	}
+}
+
+// clistAPIContest is a single contest object returned by the clist API.
+type clistAPIContest struct {
+	ID         int64  `json:"id"`
+	ResourceID int64  `json:"resource_id"`
+	Host       string `json:"host"`
+	Event      string `json:"event"`
+	Start      string `json:"start"`
+	End        string `json:"end"`
+	Duration   int64  `json:"duration"`
+	Href       string `json:"href"`
+}
+
+// clistAPIResponse is a single page of results returned by the clist API.
+type clistAPIResponse struct {
+	Meta struct {
+		Limit      int     `json:"limit"`
+		Offset     int     `json:"offset"`
+		TotalCount int     `json:"total_count"`
+		Next       *string `json:"next"`
+	} `json:"meta"`
+	Objects []clistAPIContest `json:"objects"`
+}
+
+// Name returns the name of the source.
+func (c *ClistAPISource) Name() string {
+	return "clist-api"
+}
+
+// Fetch pages through the clist API and returns every contest in the configured time window.
+func (c *ClistAPISource) Fetch(ctx context.Context) ([]model.KontestModel, error) {
+	now := time.Now().UTC()
+
+	var kontestModels []model.KontestModel
+	for offset := 0; ; offset += clistAPIPageLimit {
+		page, err := c.fetchPage(ctx, now, offset)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, contest := range page.Objects {
+			kontest, err := c.toKontestModel(contest)
+			if err != nil {
+				log.Printf("Skipping clist contest %d: %v", contest.ID, err)
+				continue
+			}
+			kontestModels = append(kontestModels, *kontest)
+		}
+
+		if page.Meta.Next == nil || len(page.Objects) < clistAPIPageLimit {
+			break
+		}
+	}
+
+	return kontestModels, nil
+}
+
+func (c *ClistAPISource) fetchPage(ctx context.Context, now time.Time, offset int) (*clistAPIResponse, error) {
+	query := url.Values{
+		"limit":     {strconv.Itoa(clistAPIPageLimit)},
+		"offset":    {strconv.Itoa(offset)},
+		"order_by":  {"start"},
+		"end__gt":   {now.Format(clistAPITimeLayout)},
+		"start__lt": {now.Add(c.window).Format(clistAPITimeLayout)},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/v4/contest/?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create clist API request: %w", err)
+	}
+	req.Header.Set("Authorization", fmt.Sprintf("ApiKey %s:%s", c.username, c.apiKey))
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch clist API contests: %w", err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("Failed to close response body: %v", err)
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch clist API contests: received status %s", resp.Status)
+	}
+
+	var page clistAPIResponse
+	if err := json.NewDecoder(resp.Body).Decode(&page); err != nil {
+		return nil, fmt.Errorf("failed to decode clist API response: %w", err)
+	}
+	return &page, nil
+}
+
+func (c *ClistAPISource) toKontestModel(contest clistAPIContest) (*model.KontestModel, error) {
+	startTime, err := time.Parse(clistAPITimeLayout, contest.Start)
+	if err != nil {
+		return nil, fmt.Errorf("invalid start time %q: %w", contest.Start, err)
+	}
+
+	// Prefer the explicit duration and only fall back to the end time when it is missing
+	endTime := startTime.Add(time.Duration(contest.Duration) * time.Second)
+	if contest.Duration == 0 {
+		endTime, err = time.Parse(clistAPITimeLayout, contest.End)
+		if err != nil {
+			return nil, fmt.Errorf("invalid end time %q: %w", contest.End, err)
+		}
+	}
+
+	kontest := model.NewKontestModel(
+		contest.Event,
+		contest.Href,
+		startTime,
+		endTime,
+		contest.Host,
+	)
+	kontest.SourceContestID = strconv.FormatInt(contest.ID, 10)
+	if contest.ResourceID != 0 {
+		kontest.SourceResourceID = strconv.FormatInt(contest.ResourceID, 10)
+	}
+	return kontest, nil
+}
@@ -0,0 +1,140 @@
+package sources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newClistAPIServer serves the pages of the contest endpoint by offset, recording the query of
+// every request and checking that it is authenticated
+func newClistAPIServer(t *testing.T, pages map[string][]byte) (*httptest.Server, *[]map[string]string) {
+	t.Helper()
+	var requests []map[string]string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/v4/contest/" {
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Authorization"); got != "ApiKey alice:secret" {
+			t.Errorf("Authorization = %q, want ApiKey alice:secret", got)
+		}
+
+		query := r.URL.Query()
+		request := make(map[string]string, len(query))
+		for name := range query {
+			request[name] = query.Get(name)
+		}
+		requests = append(requests, request)
+
+		content, ok := pages[query.Get("offset")]
+		if !ok {
+			t.Errorf("unexpected offset %q", query.Get("offset"))
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server, &requests
+}
+
+// readFixture returns the content of the fixture file
+func readFixture(t *testing.T, name string) []byte {
+	t.Helper()
+	content, err := os.ReadFile(name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return content
+}
+
+func TestClistAPISourceFetch(t *testing.T) {
+	server, requests := newClistAPIServer(t, map[string][]byte{
+		"0":   readFixture(t, "testdata/clist_api_contests_page1.json"),
+		"100": readFixture(t, "testdata/clist_api_contests_page2.json"),
+	})
+	source := NewClistAPISource(server.Client(), server.URL, "alice", "secret", 7*24*time.Hour)
+
+	before := time.Now().UTC().Truncate(time.Second)
+	kontests, err := source.Fetch(context.Background())
+	after := time.Now().UTC()
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	// The first page is full and links to the next one, which is the last
+	if len(*requests) != 2 {
+		t.Fatalf("made %d requests, want 2", len(*requests))
+	}
+	offsets := []string{(*requests)[0]["offset"], (*requests)[1]["offset"]}
+	if !slices.Equal(offsets, []string{"0", "100"}) {
+		t.Errorf("offsets = %v, want [0 100]", offsets)
+	}
+	for _, request := range *requests {
+		if request["limit"] != "100" || request["order_by"] != "start" {
+			t.Errorf("limit = %q, order_by = %q, want 100 and start", request["limit"], request["order_by"])
+		}
+
+		// Contests that have not ended and start within the window
+		endAfter, err := time.Parse(clistAPITimeLayout, request["end__gt"])
+		if err != nil || endAfter.Before(before) || endAfter.After(after) {
+			t.Errorf("end__gt = %q, want the time of the request", request["end__gt"])
+		}
+		startBefore, err := time.Parse(clistAPITimeLayout, request["start__lt"])
+		if err != nil || startBefore.Sub(endAfter) != 7*24*time.Hour {
+			t.Errorf("start__lt = %q, want a week after end__gt %q", request["start__lt"], request["end__gt"])
+		}
+	}
+
+	if len(kontests) != 102 {
+		t.Fatalf("got %d contests, want 102", len(kontests))
+	}
+
+	first := kontests[0]
+	if first.Name != "Codeforces Round 956 (Div. 2)" || first.URL != "https://codeforces.com/contests/1983" || first.SiteAbbreviation != "CodeForces" {
+		t.Errorf("first contest = %q %q %q", first.Name, first.URL, first.SiteAbbreviation)
+	}
+	if first.SourceContestID != "53472000" || first.SourceResourceID != "1" {
+		t.Errorf("first contest IDs = %q %q, want 53472000 and resource 1", first.SourceContestID, first.SourceResourceID)
+	}
+	if want := time.Date(2024, 7, 10, 14, 35, 0, 0, time.UTC); !first.StartTime.Equal(want) || !first.EndTime.Equal(want.Add(2*time.Hour)) {
+		t.Errorf("first contest runs %v to %v, want two hours from %v", first.StartTime, first.EndTime, want)
+	}
+
+	// Without a duration, the end time is used
+	heuristic := kontests[100]
+	if heuristic.Name != "AtCoder Heuristic Contest 035" || heuristic.SourceResourceID != "93" {
+		t.Errorf("heuristic contest = %q with resource %q", heuristic.Name, heuristic.SourceResourceID)
+	}
+	if want := time.Date(2024, 7, 9, 10, 0, 0, 0, time.UTC); !heuristic.EndTime.Equal(want) {
+		t.Errorf("heuristic contest EndTime = %v, want %v", heuristic.EndTime, want)
+	}
+
+	// Contests without a resource ID have none
+	if weekly := kontests[101]; weekly.SourceResourceID != "" || weekly.SiteAbbreviation != "LeetCode" {
+		t.Errorf("weekly contest resource = %q, abbreviation = %q", weekly.SourceResourceID, weekly.SiteAbbreviation)
+	}
+}
+
+func TestClistAPISourceFetchStopsOnShortPage(t *testing.T) {
+	// The page links to a further page, but it is not full, so it is the last one
+	page := strings.Replace(string(readFixture(t, "testdata/clist_api_contests_page2.json")),
+		`"next": null`, `"next": "/api/v4/contest/?limit=100&offset=100&order_by=start"`, 1)
+	server, requests := newClistAPIServer(t, map[string][]byte{"0": []byte(page)})
+	source := NewClistAPISource(server.Client(), server.URL, "alice", "secret", 7*24*time.Hour)
+
+	kontests, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+	if len(*requests) != 1 || len(kontests) != 2 {
+		t.Errorf("made %d requests for %d contests, want 1 request for 2 contests", len(*requests), len(kontests))
+	}
+}
@@ -0,0 +1,98 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"github.com/PuerkitoBio/goquery"
+	"kontest-api/model"
+	"log"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// clistTimeLayout is the layout of the UTC times in the data-ace attribute
+const clistTimeLayout = "January 2, 2006 15:04:05"
+
+// ClistSource scrapes the contest table on the clist.by home page.
+type ClistSource struct {
+	client *http.Client
+	url    string
+}
+
+// NewClistSource creates a new instance of ClistSource reading from the given URL.
+func NewClistSource(client *http.Client, url string) *ClistSource {
+	return &ClistSource{client: client, url: url}
+}
+
+// Name returns the name of the source.
+func (c *ClistSource) Name() string {
+	return "clist"
+}
+
+// Fetch downloads the clist.by home page and parses the contests listed on it.
+func (c *ClistSource) Fetch(ctx context.Context) ([]model.KontestModel, error) {
+	doc, err := fetchDocument(ctx, c.client, c.url)
+	if err != nil {
+		return nil, err
+	}
+
+	log.Println("Fetched HTML content successfully.")
+
+	return c.parseContests(doc)
+}
+
+func (c *ClistSource) parseContests(doc *goquery.Document) ([]model.KontestModel, error) {
+	var kontestModels []model.KontestModel
+
+	k := doc.Find("tr.contest")
+	log.Println("Total contest rows found:", k.Length())
+
+	k.Each(func(i int, s *goquery.Selection) {
+		name := s.Find("td.event a.title-search").Text()
+		desc, exists := s.Find("a.data-ace").Attr("data-ace")
+
+		if !exists {
+			log.Println("data-ace attribute not found for contest:", name)
+			return
+		}
+
+		// Unmarshal the JSON data (handling HTML entities)
+		desc = strings.ReplaceAll(desc, "&quot;", "\"")
+		var dataAce struct {
+			Title    string `json:"title"`
+			Desc     string `json:"desc"`
+			Location string `json:"location"`
+			Time     struct {
+				Start string `json:"start"`
+				End   string `json:"end"`
+			} `json:"time"`
+		}
+
+		if err := json.Unmarshal([]byte(desc), &dataAce); err != nil {
+			log.Printf("Failed to unmarshal JSON: %v\n", err)
+			return
+		}
+
+		// Extract URL from the desc
+		url := strings.Replace(dataAce.Desc, "url: ", "", 1)
+
+		startTime, err := time.Parse(clistTimeLayout, dataAce.Time.Start)
+		if err != nil {
+			log.Printf("Failed to parse start time for contest %s: %v", name, err)
+			return
+		}
+		endTime, err := time.Parse(clistTimeLayout, dataAce.Time.End)
+		if err != nil {
+			log.Printf("Failed to parse end time for contest %s: %v", name, err)
+			return
+		}
+
+		kontest := model.NewKontestModel(name, url, startTime, endTime, dataAce.Location)
+
+		kontestModels = append(kontestModels, *kontest)
+	})
+
+	log.Println("Parsed contests successfully.")
+	return kontestModels, nil
+}
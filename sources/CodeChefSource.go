@@ -0,0 +1,137 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"kontest-api/model"
+	"log"
+	"net/http"
+	"net/url"
+	"time"
+)
+
+// CodeChefSource reads the present and future contests from the contest list API that the
+// CodeChef contest listing page is rendered from. The page itself is rendered in the browser,
+// so its HTML does not contain the contests.
+type CodeChefSource struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewCodeChefSource creates a new instance of CodeChefSource using the given base URL,
+// e.g. "https://www.codechef.com".
+func NewCodeChefSource(client *http.Client, baseURL string) *CodeChefSource {
+	return &CodeChefSource{client: client, baseURL: baseURL}
+}
+
+// codeChefContest is a single contest of the contest list.
+type codeChefContest struct {
+	Code      string `json:"contest_code"`
+	Name      string `json:"contest_name"`
+	StartTime string `json:"contest_start_date_iso"`
+	EndTime   string `json:"contest_end_date_iso"`
+}
+
+// codeChefResponse is the contest list. The lists are pointers so that a response without them,
+// e.g. after an API change, can be told apart from a response with no contests.
+type codeChefResponse struct {
+	Status          string             `json:"status"`
+	Message         string             `json:"message"`
+	PresentContests *[]codeChefContest `json:"present_contests"`
+	FutureContests  *[]codeChefContest `json:"future_contests"`
+}
+
+// Name returns the name of the source.
+func (c *CodeChefSource) Name() string {
+	return "codechef"
+}
+
+// Fetch downloads the CodeChef contest list and parses the present and future contests.
+func (c *CodeChefSource) Fetch(ctx context.Context) ([]model.KontestModel, error) {
+	query := url.Values{
+		"sort_by":       {"START"},
+		"sorting_order": {"asc"},
+		"offset":        {"0"},
+		"mode":          {"all"},
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+"/api/list/contests/all?"+query.Encode(), nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create CodeChef request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch CodeChef contests: %w", err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("Failed to close response body: %v", err)
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch CodeChef contests: received status %s", resp.Status)
+	}
+	return c.parseContests(resp.Body)
+}
+
+// parseContests parses the present and future contests of the contest list. A response without
+// either list means the API has changed; it fails instead of reporting that there are no contests.
+func (c *CodeChefSource) parseContests(body io.Reader) ([]model.KontestModel, error) {
+	var response codeChefResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode CodeChef response: %w", err)
+	}
+
+	if response.Status != "success" {
+		return nil, fmt.Errorf("codechef API returned status %q: %s", response.Status, response.Message)
+	}
+	if response.PresentContests == nil && response.FutureContests == nil {
+		return nil, errors.New("failed to parse CodeChef contests: contest lists not found, the API may have changed")
+	}
+
+	var contests []codeChefContest
+	if response.PresentContests != nil {
+		contests = append(contests, *response.PresentContests...)
+	}
+	if response.FutureContests != nil {
+		contests = append(contests, *response.FutureContests...)
+	}
+
+	var kontestModels []model.KontestModel
+	for _, contest := range contests {
+		if contest.Code == "" || contest.Name == "" {
+			log.Println("Skipping CodeChef contest without a contest code or name")
+			continue
+		}
+
+		startTime, err := time.Parse(time.RFC3339, contest.StartTime)
+		if err != nil {
+			log.Printf("Failed to parse CodeChef start time for %s: %v", contest.Code, err)
+			continue
+		}
+		endTime, err := time.Parse(time.RFC3339, contest.EndTime)
+		if err != nil {
+			log.Printf("Failed to parse CodeChef end time for %s: %v", contest.Code, err)
+			continue
+		}
+
+		kontest := model.NewKontestModel(
+			contest.Name,
+			"https://www.codechef.com/"+contest.Code,
+			startTime.UTC(),
+			endTime.UTC(),
+			"codechef.com",
+		)
+		kontest.SourceContestID = contest.Code
+
+		kontestModels = append(kontestModels, *kontest)
+	}
+
+	return kontestModels, nil
+}
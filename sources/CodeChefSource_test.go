@@ -0,0 +1,86 @@
+package sources
+
+import (
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCodeChefParseContests(t *testing.T) {
+	file, err := os.Open("testdata/codechef_contests.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer file.Close()
+
+	kontests, err := NewCodeChefSource(nil, "https://www.codechef.com").parseContests(file)
+	if err != nil {
+		t.Fatalf("parseContests failed: %v", err)
+	}
+
+	// The present and future contests are listed, not the past ones
+	want := []struct {
+		name, url, code string
+		start, end      time.Time
+	}{
+		{
+			name:  "Starters 141 (Div. 1)",
+			url:   "https://www.codechef.com/START141",
+			code:  "START141",
+			start: time.Date(2024, 7, 3, 14, 30, 0, 0, time.UTC),
+			end:   time.Date(2024, 7, 3, 16, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "July Long Challenge 2024",
+			url:   "https://www.codechef.com/LTIME2407",
+			code:  "LTIME2407",
+			start: time.Date(2024, 7, 1, 9, 30, 0, 0, time.UTC),
+			end:   time.Date(2024, 7, 11, 9, 30, 0, 0, time.UTC),
+		},
+		{
+			name:  "Starters 142",
+			url:   "https://www.codechef.com/START142",
+			code:  "START142",
+			start: time.Date(2024, 7, 10, 14, 30, 0, 0, time.UTC),
+			end:   time.Date(2024, 7, 10, 16, 30, 0, 0, time.UTC),
+		},
+	}
+	if len(kontests) != len(want) {
+		t.Fatalf("got %d contests, want %d: %+v", len(kontests), len(want), kontests)
+	}
+	for i, w := range want {
+		got := kontests[i]
+		if got.Name != w.name || got.URL != w.url || got.SourceContestID != w.code {
+			t.Errorf("contest %d = %q %q %q, want %q %q %q", i, got.Name, got.URL, got.SourceContestID, w.name, w.url, w.code)
+		}
+		if !got.StartTime.Equal(w.start) || got.StartTime.Location() != time.UTC {
+			t.Errorf("%s: StartTime = %v, want %v", w.name, got.StartTime, w.start)
+		}
+		if !got.EndTime.Equal(w.end) || got.EndTime.Location() != time.UTC {
+			t.Errorf("%s: EndTime = %v, want %v", w.name, got.EndTime, w.end)
+		}
+		if got.SiteAbbreviation != "CodeChef" {
+			t.Errorf("%s: SiteAbbreviation = %q, want CodeChef", w.name, got.SiteAbbreviation)
+		}
+	}
+}
+
+func TestCodeChefParseContestsErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		body string
+	}{
+		{name: "contest lists missing", body: `{"status": "success", "message": "All contests list", "banners": []}`},
+		{name: "error status", body: `{"status": "error", "message": "Too many requests"}`},
+		{name: "HTML page", body: `<!DOCTYPE html><html><body><div id="root"></div></body></html>`},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			kontests, err := NewCodeChefSource(nil, "https://www.codechef.com").parseContests(strings.NewReader(tt.body))
+			if err == nil {
+				t.Fatalf("parseContests returned %d contests, want an error", len(kontests))
+			}
+		})
+	}
+}
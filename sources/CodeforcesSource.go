@@ -0,0 +1,147 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"kontest-api/model"
+	"log"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// CodeforcesSource reads contests from the Codeforces contest.list API.
+type CodeforcesSource struct {
+	client     *http.Client
+	baseURL    string
+	includeGym bool
+}
+
+// NewCodeforcesSource creates a new instance of CodeforcesSource using the given API base URL,
+// e.g. "https://codeforces.com". Gym contests are only fetched when includeGym is set.
+func NewCodeforcesSource(client *http.Client, baseURL string, includeGym bool) *CodeforcesSource {
+	return &CodeforcesSource{
+		client:     client,
+		baseURL:    baseURL,
+		includeGym: includeGym,
+	}
+}
+
+// codeforcesContest is a single entry of the contest.list result.
+type codeforcesContest struct {
+	ID               int    `json:"id"`
+	Name             string `json:"name"`
+	Type             string `json:"type"`
+	Phase            string `json:"phase"`
+	DurationSeconds  int64  `json:"durationSeconds"`
+	StartTimeSeconds int64  `json:"startTimeSeconds"`
+}
+
+// codeforcesResponse is the envelope returned by every Codeforces API method.
+type codeforcesResponse struct {
+	Status  string              `json:"status"`
+	Comment string              `json:"comment"`
+	Result  []codeforcesContest `json:"result"`
+}
+
+// Name returns the name of the source.
+func (c *CodeforcesSource) Name() string {
+	return "codeforces"
+}
+
+// Fetch retrieves the upcoming and running Codeforces contests, and gym contests if enabled.
+func (c *CodeforcesSource) Fetch(ctx context.Context) ([]model.KontestModel, error) {
+	kontests, err := c.fetchContests(ctx, false)
+	if err != nil {
+		return nil, err
+	}
+
+	if c.includeGym {
+		gymKontests, err := c.fetchContests(ctx, true)
+		if err != nil {
+			return nil, err
+		}
+		kontests = append(kontests, gymKontests...)
+	}
+
+	return kontests, nil
+}
+
+func (c *CodeforcesSource) fetchContests(ctx context.Context, gym bool) ([]model.KontestModel, error) {
+	endpoint := c.baseURL + "/api/contest.list?" + url.Values{"gym": {strconv.FormatBool(gym)}}.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, endpoint, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create Codeforces request: %w", err)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch Codeforces contests: %w", err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("Failed to close response body: %v", err)
+		}
+	}(resp.Body)
+
+	kontests, err := c.parseContests(resp.Body, gym)
+	// Server errors often come with an HTML page instead of a JSON body, so report the status too
+	if err != nil && resp.StatusCode >= http.StatusInternalServerError {
+		return nil, fmt.Errorf("failed to fetch Codeforces contests: received status %s: %w", resp.Status, err)
+	}
+	return kontests, err
+}
+
+func (c *CodeforcesSource) parseContests(body io.Reader, gym bool) ([]model.KontestModel, error) {
+	// The API reports failures as a JSON body with a non-OK status, so decode before checking it
+	var response codeforcesResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode Codeforces response: %w", err)
+	}
+
+	if response.Status != "OK" {
+		return nil, fmt.Errorf("codeforces API returned status %s: %s", response.Status, response.Comment)
+	}
+
+	location := "codeforces.com"
+	path := "contest"
+	if gym {
+		location = "codeforces.com/gym"
+		path = "gym"
+	}
+
+	var kontestModels []model.KontestModel
+	for _, contest := range response.Result {
+		// Only keep contests that have not finished yet, matching what clist lists
+		if contest.Phase == "FINISHED" {
+			continue
+		}
+
+		// Some gym contests are listed without a start time
+		if contest.StartTimeSeconds == 0 {
+			continue
+		}
+
+		startTime := time.Unix(contest.StartTimeSeconds, 0).UTC()
+		endTime := startTime.Add(time.Duration(contest.DurationSeconds) * time.Second)
+		contestURL := fmt.Sprintf("https://codeforces.com/%s/%d", path, contest.ID)
+
+		kontest := model.NewKontestModel(
+			contest.Name,
+			contestURL,
+			startTime,
+			endTime,
+			location,
+		)
+		kontest.SourceContestID = strconv.Itoa(contest.ID)
+
+		kontestModels = append(kontestModels, *kontest)
+	}
+
+	return kontestModels, nil
+}
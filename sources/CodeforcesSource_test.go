@@ -0,0 +1,163 @@
+package sources
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newCodeforcesServer serves the recorded contest.list responses, picking the fixture by the gym flag
+func newCodeforcesServer(t *testing.T) *httptest.Server {
+	t.Helper()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/contest.list" {
+			http.NotFound(w, r)
+			return
+		}
+
+		var fixture string
+		switch r.URL.Query().Get("gym") {
+		case "false":
+			fixture = "testdata/codeforces_contests.json"
+		case "true":
+			fixture = "testdata/codeforces_gym_contests.json"
+		default:
+			t.Errorf("unexpected gym parameter %q", r.URL.Query().Get("gym"))
+			http.Error(w, "bad request", http.StatusBadRequest)
+			return
+		}
+
+		content, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Errorf("failed to read %s: %v", fixture, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestCodeforcesSourceFetch(t *testing.T) {
+	server := newCodeforcesServer(t)
+	source := NewCodeforcesSource(server.Client(), server.URL, false)
+
+	kontests, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	// The finished round is left out
+	if len(kontests) != 2 {
+		t.Fatalf("got %d contests, want 2: %+v", len(kontests), kontests)
+	}
+
+	upcoming := kontests[0]
+	if upcoming.Name != "Codeforces Round 956 (Div. 2) and ByteRace 2024" {
+		t.Errorf("Name = %q", upcoming.Name)
+	}
+	if upcoming.URL != "https://codeforces.com/contest/1983" {
+		t.Errorf("URL = %q", upcoming.URL)
+	}
+	if upcoming.SiteAbbreviation != "CodeForces" {
+		t.Errorf("SiteAbbreviation = %q, want CodeForces", upcoming.SiteAbbreviation)
+	}
+	if upcoming.SourceContestID != "1983" {
+		t.Errorf("SourceContestID = %q, want 1983", upcoming.SourceContestID)
+	}
+	if want := time.Date(2024, 7, 11, 14, 35, 0, 0, time.UTC); !upcoming.StartTime.Equal(want) {
+		t.Errorf("StartTime = %v, want %v", upcoming.StartTime, want)
+	}
+	if want := time.Date(2024, 7, 11, 16, 35, 0, 0, time.UTC); !upcoming.EndTime.Equal(want) {
+		t.Errorf("EndTime = %v, want %v", upcoming.EndTime, want)
+	}
+
+	if running := kontests[1]; running.URL != "https://codeforces.com/contest/1982" {
+		t.Errorf("running contest URL = %q", running.URL)
+	}
+}
+
+func TestCodeforcesSourceFetchWithGym(t *testing.T) {
+	server := newCodeforcesServer(t)
+	source := NewCodeforcesSource(server.Client(), server.URL, true)
+
+	kontests, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	// Two regular contests, and the one gym contest that has a start time and has not finished
+	if len(kontests) != 3 {
+		t.Fatalf("got %d contests, want 3: %+v", len(kontests), kontests)
+	}
+
+	gym := kontests[2]
+	if gym.SiteAbbreviation != "CodeForcesGym" {
+		t.Errorf("SiteAbbreviation = %q, want CodeForcesGym", gym.SiteAbbreviation)
+	}
+	if gym.URL != "https://codeforces.com/gym/105247" {
+		t.Errorf("URL = %q", gym.URL)
+	}
+	if want := time.Date(2024, 7, 13, 7, 0, 0, 0, time.UTC); !gym.StartTime.Equal(want) {
+		t.Errorf("StartTime = %v, want %v", gym.StartTime, want)
+	}
+	for _, kontest := range kontests {
+		if kontest.StartTime.Unix() == 0 {
+			t.Errorf("contest %q without a start time was kept", kontest.Name)
+		}
+	}
+}
+
+func TestCodeforcesSourceFetchErrors(t *testing.T) {
+	failed, err := os.ReadFile("testdata/codeforces_failed.json")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name        string
+		status      int
+		contentType string
+		body        string
+		wantErr     string
+	}{
+		{
+			name:        "failed status",
+			status:      http.StatusBadRequest,
+			contentType: "application/json",
+			body:        string(failed),
+			wantErr:     "FAILED: gym: Field should contain boolean value",
+		},
+		{
+			name:        "server error with an HTML body",
+			status:      http.StatusBadGateway,
+			contentType: "text/html",
+			body:        "<html><body><h1>502 Bad Gateway</h1></body></html>",
+			wantErr:     "received status 502 Bad Gateway",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", tt.contentType)
+				w.WriteHeader(tt.status)
+				w.Write([]byte(tt.body))
+			}))
+			defer server.Close()
+
+			source := NewCodeforcesSource(server.Client(), server.URL, false)
+			kontests, err := source.Fetch(context.Background())
+			if err == nil {
+				t.Fatalf("Fetch returned %d contests, want an error", len(kontests))
+			}
+			if !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("error %q does not mention %q", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,16 @@
+package sources
+
+import (
+	"context"
+	"kontest-api/model"
+)
+
+// ContestSource fetches contests from an upstream site and parses them into models.
+type ContestSource interface {
+	// Name returns the unique name the source is registered under.
+	Name() string
+
+	// Fetch retrieves the contests currently listed by the source. It stops when the context
+	// is cancelled.
+	Fetch(ctx context.Context) ([]model.KontestModel, error)
+}
@@ -0,0 +1,121 @@
+package sources
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"kontest-api/model"
+	"log"
+	"net/http"
+	"time"
+)
+
+// leetCodeUpcomingContestsQuery lists the upcoming weekly and biweekly contests.
+const leetCodeUpcomingContestsQuery = `query upcomingContests {
+	upcomingContests {
+		title
+		titleSlug
+		startTime
+		duration
+	}
+}`
+
+// LeetCodeSource reads the upcoming weekly and biweekly contests from the LeetCode GraphQL API.
+type LeetCodeSource struct {
+	client  *http.Client
+	baseURL string
+}
+
+// NewLeetCodeSource creates a new instance of LeetCodeSource using the given base URL,
+// e.g. "https://leetcode.com".
+func NewLeetCodeSource(client *http.Client, baseURL string) *LeetCodeSource {
+	return &LeetCodeSource{client: client, baseURL: baseURL}
+}
+
+// leetCodeContest is a single entry of the upcomingContests query.
+type leetCodeContest struct {
+	Title     string `json:"title"`
+	TitleSlug string `json:"titleSlug"`
+	StartTime int64  `json:"startTime"`
+	Duration  int64  `json:"duration"`
+}
+
+// leetCodeResponse is the GraphQL response envelope for the upcomingContests query.
+type leetCodeResponse struct {
+	Data struct {
+		UpcomingContests []leetCodeContest `json:"upcomingContests"`
+	} `json:"data"`
+	Errors []struct {
+		Message string `json:"message"`
+	} `json:"errors"`
+}
+
+// Name returns the name of the source.
+func (l *LeetCodeSource) Name() string {
+	return "leetcode"
+}
+
+// Fetch retrieves the upcoming LeetCode contests.
+func (l *LeetCodeSource) Fetch(ctx context.Context) ([]model.KontestModel, error) {
+	payload, err := json.Marshal(map[string]string{"query": leetCodeUpcomingContestsQuery})
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode LeetCode query: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, l.baseURL+"/graphql", bytes.NewReader(payload))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LeetCode request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	// LeetCode rejects GraphQL requests that do not look like they come from its own site
+	req.Header.Set("Referer", l.baseURL+"/contest/")
+
+	resp, err := l.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch LeetCode contests: %w", err)
+	}
+	defer func(Body io.ReadCloser) {
+		err := Body.Close()
+		if err != nil {
+			log.Printf("Failed to close response body: %v", err)
+		}
+	}(resp.Body)
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch LeetCode contests: received status %s", resp.Status)
+	}
+
+	return l.parseContests(resp.Body)
+}
+
+func (l *LeetCodeSource) parseContests(body io.Reader) ([]model.KontestModel, error) {
+	var response leetCodeResponse
+	if err := json.NewDecoder(body).Decode(&response); err != nil {
+		return nil, fmt.Errorf("failed to decode LeetCode response: %w", err)
+	}
+
+	if len(response.Errors) > 0 {
+		return nil, fmt.Errorf("leetcode API returned an error: %s", response.Errors[0].Message)
+	}
+
+	var kontestModels []model.KontestModel
+	for _, contest := range response.Data.UpcomingContests {
+		startTime := time.Unix(contest.StartTime, 0).UTC()
+		endTime := startTime.Add(time.Duration(contest.Duration) * time.Second)
+
+		kontest := model.NewKontestModel(
+			contest.Title,
+			"https://leetcode.com/contest/"+contest.TitleSlug,
+			startTime,
+			endTime,
+			"leetcode.com",
+		)
+		kontest.SourceContestID = contest.TitleSlug
+
+		kontestModels = append(kontestModels, *kontest)
+	}
+
+	return kontestModels, nil
+}
@@ -0,0 +1,113 @@
+package sources
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+	"time"
+)
+
+// newLeetCodeServer serves the recorded GraphQL response in the fixture, checking that the request
+// is the upcomingContests query sent the way the LeetCode site sends it
+func newLeetCodeServer(t *testing.T, fixture string) *httptest.Server {
+	t.Helper()
+	var server *httptest.Server
+	server = httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.URL.Path != "/graphql" {
+			t.Errorf("unexpected request %s %s", r.Method, r.URL.Path)
+			http.NotFound(w, r)
+			return
+		}
+		if got := r.Header.Get("Content-Type"); got != "application/json" {
+			t.Errorf("Content-Type = %q, want application/json", got)
+		}
+		if got, want := r.Header.Get("Referer"), server.URL+"/contest/"; got != want {
+			t.Errorf("Referer = %q, want %q", got, want)
+		}
+
+		var body struct {
+			Query string `json:"query"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			t.Errorf("failed to decode request body: %v", err)
+		}
+		if body.Query != leetCodeUpcomingContestsQuery {
+			t.Errorf("query = %q, want the upcomingContests query", body.Query)
+		}
+
+		content, err := os.ReadFile(fixture)
+		if err != nil {
+			t.Errorf("failed to read %s: %v", fixture, err)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Write(content)
+	}))
+	t.Cleanup(server.Close)
+	return server
+}
+
+func TestLeetCodeSourceFetch(t *testing.T) {
+	server := newLeetCodeServer(t, "testdata/leetcode_upcoming_contests.json")
+	source := NewLeetCodeSource(server.Client(), server.URL)
+
+	kontests, err := source.Fetch(context.Background())
+	if err != nil {
+		t.Fatalf("Fetch failed: %v", err)
+	}
+
+	want := []struct {
+		name, url, slug string
+		start, end      time.Time
+	}{
+		{
+			name:  "Biweekly Contest 134",
+			url:   "https://leetcode.com/contest/biweekly-contest-134",
+			slug:  "biweekly-contest-134",
+			start: time.Date(2024, 7, 6, 14, 30, 0, 0, time.UTC),
+			end:   time.Date(2024, 7, 6, 16, 0, 0, 0, time.UTC),
+		},
+		{
+			name:  "Weekly Contest 406",
+			url:   "https://leetcode.com/contest/weekly-contest-406",
+			slug:  "weekly-contest-406",
+			start: time.Date(2024, 7, 7, 2, 30, 0, 0, time.UTC),
+			end:   time.Date(2024, 7, 7, 4, 0, 0, 0, time.UTC),
+		},
+	}
+	if len(kontests) != len(want) {
+		t.Fatalf("got %d contests, want %d: %+v", len(kontests), len(want), kontests)
+	}
+	for i, w := range want {
+		got := kontests[i]
+		if got.Name != w.name || got.URL != w.url || got.SourceContestID != w.slug {
+			t.Errorf("contest %d = %q %q %q, want %q %q %q", i, got.Name, got.URL, got.SourceContestID, w.name, w.url, w.slug)
+		}
+		if !got.StartTime.Equal(w.start) || got.StartTime.Location() != time.UTC {
+			t.Errorf("%s: StartTime = %v, want %v", w.name, got.StartTime, w.start)
+		}
+		if !got.EndTime.Equal(w.end) {
+			t.Errorf("%s: EndTime = %v, want %v", w.name, got.EndTime, w.end)
+		}
+		if got.SiteAbbreviation != "LeetCode" {
+			t.Errorf("%s: SiteAbbreviation = %q, want LeetCode", w.name, got.SiteAbbreviation)
+		}
+	}
+}
+
+func TestLeetCodeSourceFetchErrors(t *testing.T) {
+	server := newLeetCodeServer(t, "testdata/leetcode_errors.json")
+	source := NewLeetCodeSource(server.Client(), server.URL)
+
+	kontests, err := source.Fetch(context.Background())
+	if err == nil {
+		t.Fatalf("Fetch returned %d contests, want an error", len(kontests))
+	}
+	if !strings.Contains(err.Error(), `Cannot query field "upcomingContests"`) {
+		t.Errorf("error %q does not carry the GraphQL error message", err)
+	}
+}
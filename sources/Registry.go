@@ -0,0 +1,74 @@
+package sources
+
+import "sync"
+
+// Registry keeps track of the available contest sources and which of them are enabled.
+type Registry struct {
+	mu       sync.RWMutex
+	sources  []ContestSource
+	disabled map[string]bool
+}
+
+// NewRegistry creates an empty Registry.
+func NewRegistry() *Registry {
+	return &Registry{
+		disabled: make(map[string]bool),
+	}
+}
+
+// Register adds a source to the registry, replacing any source already registered under the same name.
+func (r *Registry) Register(source ContestSource) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	for i, existing := range r.sources {
+		if existing.Name() == source.Name() {
+			r.sources[i] = source
+			return
+		}
+	}
+	r.sources = append(r.sources, source)
+}
+
+// Enable marks the named source as enabled. Sources are enabled by default.
+func (r *Registry) Enable(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	delete(r.disabled, name)
+}
+
+// Disable marks the named source as disabled so it is skipped during refreshes.
+func (r *Registry) Disable(name string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.disabled[name] = true
+}
+
+// Get returns the source registered under the given name.
+func (r *Registry) Get(name string) (ContestSource, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for _, source := range r.sources {
+		if source.Name() == name {
+			return source, true
+		}
+	}
+	return nil, false
+}
+
+// Enabled returns the enabled sources in registration order.
+func (r *Registry) Enabled() []ContestSource {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var enabled []ContestSource
+	for _, source := range r.sources {
+		if !r.disabled[source.Name()] {
+			enabled = append(enabled, source)
+		}
+	}
+	return enabled
+}
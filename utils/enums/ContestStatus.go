@@ -0,0 +1,16 @@
+package enums
+
+// ContestStatus represents the state of a contest relative to the current time.
+type ContestStatus string
+
+// List of all contest statuses.
+const (
+	StatusUpcoming ContestStatus = "upcoming"
+	StatusRunning  ContestStatus = "running"
+	StatusFinished ContestStatus = "finished"
+)
+
+// GetAllStatuses returns a list of all contest statuses.
+func GetAllStatuses() []ContestStatus {
+	return []ContestStatus{StatusUpcoming, StatusRunning, StatusFinished}
+}